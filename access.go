@@ -0,0 +1,103 @@
+package rbadger
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeyCount key及其访问次数
+type KeyCount struct {
+	Key   string
+	Count int64
+}
+
+// accessDecayFactor 每记录 maxKeys*accessDecayFactor 次读取后对所有计数做一次衰减
+const accessDecayFactor = 10
+
+// accessTracker 记录key的读取次数，跟踪的key数量有上限
+type accessTracker struct {
+	mu      sync.Mutex
+	maxKeys int
+	counts  map[string]int64
+	hits    int // 自上次衰减以来记录的读取次数
+}
+
+// hit 记录一次读取
+// 每记录 maxKeys*accessDecayFactor 次读取后将所有计数减半并移除归零的key，
+// 衰减的开销分摊到每次读取上为O(1)。跟踪的key已满时忽略新key，直到下一次衰减腾出位置，
+// 这样访问频繁的key始终保留，偶发访问的key会逐渐被淘汰，且大量只访问一次的key不会使热点key的计数归零
+func (t *accessTracker) hit(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.hits++; t.hits >= t.maxKeys*accessDecayFactor {
+		t.hits = 0
+		for k, n := range t.counts {
+			if n /= 2; n == 0 {
+				delete(t.counts, k)
+			} else {
+				t.counts[k] = n
+			}
+		}
+	}
+
+	if _, ok := t.counts[key]; !ok && len(t.counts) >= t.maxKeys {
+		return
+	}
+	t.counts[key]++
+}
+
+// EnableAccessTracking 开启或关闭 Get/GetS/GetBytes 的读取次数统计
+// 统计仅保存在内存中，重启后重置；最多跟踪maxKeys个key，超过时按衰减方式淘汰访问较少的key，
+// 因此计数为近似值。maxKeys<=0 表示关闭统计并清空已有数据
+// 示例：
+//
+//	db.EnableAccessTracking(10000)
+//	// ...
+//	for _, kc := range db.TopKeys(10) {
+//	    fmt.Println(kc.Key, kc.Count)
+//	}
+func (b *BadgerDB) EnableAccessTracking(maxKeys int) {
+	if maxKeys <= 0 {
+		b.access.Store(nil)
+		return
+	}
+	b.access.Store(&accessTracker{maxKeys: maxKeys, counts: make(map[string]int64)})
+}
+
+// TopKeys 返回读取次数最多的n个key，按次数从高到低排列
+// 未开启 EnableAccessTracking 时返回nil
+// 示例：
+//
+//	hot := db.TopKeys(10)
+func (b *BadgerDB) TopKeys(n int) []KeyCount {
+	t := b.access.Load()
+	if t == nil || n <= 0 {
+		return nil
+	}
+
+	t.mu.Lock()
+	result := make([]KeyCount, 0, len(t.counts))
+	for key, count := range t.counts {
+		result = append(result, KeyCount{Key: key, Count: count})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Key < result[j].Key
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// trackAccess 在开启统计时记录一次读取
+func (b *BadgerDB) trackAccess(key []byte) {
+	if t := b.access.Load(); t != nil {
+		t.hit(string(key))
+	}
+}
@@ -0,0 +1,57 @@
+package rbadger
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestTopKeys 测试读取次数统计
+func TestTopKeys(t *testing.T) {
+	db := newTestDB(t)
+
+	db.GetS("a")
+	if db.TopKeys(1) != nil {
+		t.Error("未开启时应返回nil")
+	}
+
+	db.EnableAccessTracking(2)
+	for i := 0; i < 5; i++ {
+		db.GetS("hot")
+	}
+	for i := 0; i < 2; i++ {
+		db.GetS("warm")
+	}
+
+	top := db.TopKeys(1)
+	if len(top) != 1 || top[0].Key != "hot" || top[0].Count != 5 {
+		t.Errorf("统计结果不正确: %v", top)
+	}
+
+	// 超过上限时淘汰访问较少的key
+	db.GetS("cold")
+	top = db.TopKeys(10)
+	if len(top) != 2 || top[0].Key != "hot" {
+		t.Errorf("淘汰结果不正确: %v", top)
+	}
+
+	db.EnableAccessTracking(0)
+	if db.TopKeys(1) != nil {
+		t.Error("关闭后应返回nil")
+	}
+}
+
+// TestTopKeysColdStream 测试大量只访问一次的key不会使热点key的计数归零
+func TestTopKeysColdStream(t *testing.T) {
+	db := newTestDB(t)
+
+	db.EnableAccessTracking(10)
+	for i := 0; i < 1000; i++ {
+		db.GetS("hot")
+		db.GetS(fmt.Sprintf("cold:%d", i))
+	}
+
+	top := db.TopKeys(1)
+	if len(top) != 1 || top[0].Key != "hot" || top[0].Count < 10 {
+		t.Errorf("热点key的计数不应被冷key衰减: %v", top)
+	}
+}
@@ -0,0 +1,553 @@
+package rbadger
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// maxTxnRetries 事务冲突时的最大重试次数
+const maxTxnRetries = 100
+
+// updateWithRetry 在写事务中执行fn，遇到事务冲突时自动重试
+// fn 可能被多次调用，因此不应在其中产生除事务外的副作用
+func (b *BadgerDB) updateWithRetry(fn func(txn *badger.Txn) error) error {
+	var err error
+	for i := 0; i < maxTxnRetries; i++ {
+		err = b.db.Update(fn)
+		if err != badger.ErrConflict {
+			return err
+		}
+	}
+	return err
+}
+
+// SetNX 仅当key不存在时写入，返回是否写入
+// 检查与写入在同一个事务中完成，遇到并发冲突时自动重试，因此多个调用方同时写入同一个key时只有一个会成功
+// 示例：
+//
+//	ok, err := db.SetNX("lock:job1", []byte("worker-1"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !ok {
+//	    // 已被其他调用方持有
+//	}
+func (b *BadgerDB) SetNX(key string, value []byte) (bool, error) {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return false, err
+	}
+	defer b.invalidate(key)
+
+	var written bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		written = false
+		_, err := txn.Get([]byte(key))
+		if err == nil {
+			return nil
+		}
+		if err != badger.ErrKeyNotFound {
+			return err
+		}
+		written = true
+		return txn.Set([]byte(key), value)
+	})
+	if err != nil {
+		return false, err
+	}
+	return written, nil
+}
+
+// SetNXS 仅当key不存在时写入字符串值，返回是否写入
+// 示例：
+//
+//	ok, err := db.SetNXS("lock:job1", "worker-1")
+func (b *BadgerDB) SetNXS(key string, value string) (bool, error) {
+	return b.SetNX(key, []byte(value))
+}
+
+// GetSet 写入新值并返回key原有的值，key不存在时返回nil
+// 读取与写入在同一个事务中完成，遇到并发冲突时自动重试
+// 示例：
+//
+//	old, err := db.GetSet("config:version", []byte("2"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetSet(key string, value []byte) (old []byte, err error) {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return nil, err
+	}
+	defer b.invalidate(key)
+
+	err = b.updateWithRetry(func(txn *badger.Txn) error {
+		old = nil
+		item, err := txn.Get([]byte(key))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			if old, err = item.ValueCopy(nil); err != nil {
+				return err
+			}
+		}
+		return txn.Set([]byte(key), value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// GetSetS 写入新的字符串值并返回key原有的值，key不存在时返回空字符串
+// 示例：
+//
+//	old, err := db.GetSetS("config:version", "2")
+func (b *BadgerDB) GetSetS(key string, value string) (string, error) {
+	old, err := b.GetSet(key, []byte(value))
+	return string(old), err
+}
+
+// GetDel 读取key的值并删除该key，key不存在时返回 ErrNotFound
+// 读取与删除在同一个事务中完成，遇到并发冲突时自动重试，
+// 因此多个调用方同时读取同一个key时只有一个能取到值，适用于一次性令牌等场景
+// 示例：
+//
+//	value, err := db.GetDel("token:abc")
+//	if errors.Is(err, rbadger.ErrNotFound) {
+//	    fmt.Println("令牌不存在或已被使用")
+//	}
+func (b *BadgerDB) GetDel(key string) ([]byte, error) {
+	defer b.invalidate(key)
+
+	var value []byte
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		if value, err = item.ValueCopy(nil); err != nil {
+			return err
+		}
+		return txn.Delete([]byte(key))
+	})
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	b.noteDeletes(1)
+	return value, nil
+}
+
+// GetDelS 读取key的字符串值并删除该key
+// 示例：
+//
+//	value, err := db.GetDelS("token:abc")
+func (b *BadgerDB) GetDelS(key string) (string, error) {
+	value, err := b.GetDel(key)
+	return string(value), err
+}
+
+// XGetDel 读取带过期时间存储的key并删除该key，key不存在或已过期时返回nil
+// 读取、过期检查与删除在同一个事务中完成，已过期的key同样会被删除
+// 示例：
+//
+//	value, err := db.XGetDel("captcha:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("验证码不存在或已过期")
+//	}
+func (b *BadgerDB) XGetDel(key string) ([]byte, error) {
+	defer b.invalidate(key)
+
+	var value []byte
+	var expired bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		value, expired = nil, false
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		var cache CacheType
+		err = item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			return err
+		})
+		if err != nil {
+			return &CorruptEntryError{Key: key, Err: err}
+		}
+
+		if cache.expiredAt(time.Now().Unix()) {
+			expired = true
+		} else if value = cache.Data; value == nil {
+			value = []byte{}
+		}
+		return txn.Delete([]byte(key))
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.noteDeletes(1)
+	if expired {
+		b.fireExpire([]byte(key))
+		return nil, nil
+	}
+	return value, nil
+}
+
+// CompareAndSwap 仅当key当前的值与old逐字节相等时写入new，返回是否写入
+// key不存在时视为不相等并返回false。比较与写入在同一个事务中完成，遇到并发冲突时自动重试
+// 示例：
+//
+//	for {
+//	    old, _ := db.Get("flag")
+//	    ok, err := db.CompareAndSwap("flag", old, toggle(old))
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    if ok {
+//	        break
+//	    }
+//	}
+func (b *BadgerDB) CompareAndSwap(key string, old, new []byte) (bool, error) {
+	if err := b.cfg.checkValueLen(new); err != nil {
+		return false, err
+	}
+	defer b.invalidate(key)
+
+	var swapped bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		swapped = false
+		item, err := txn.Get([]byte(key))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var equal bool
+		if err := item.Value(func(val []byte) error {
+			equal = bytes.Equal(val, old)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if !equal {
+			return nil
+		}
+		swapped = true
+		return txn.Set([]byte(key), new)
+	})
+	if err != nil {
+		return false, err
+	}
+	return swapped, nil
+}
+
+// XIncrByMulti 在一个事务中将多个key存储的数字值分别增加指定的值，并返回各自的新值
+// 所有key要么全部更新成功，要么全部不更新；遇到并发冲突时自动重试。
+// 任一结果超出int64范围时返回 ErrIntegerOverflow，且不更新任何key
+// 示例：
+//
+//	values, err := db.XIncrByMulti(map[string]int64{
+//	    "stats:total":     1,
+//	    "stats:category:a": 1,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("总数: %d\n", values["stats:total"])
+func (b *BadgerDB) XIncrByMulti(increments map[string]int64) (map[string]int64, error) {
+	defer b.purgeReadCache()
+
+	var result map[string]int64
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		result = make(map[string]int64, len(increments))
+		now := time.Now().Unix()
+
+		for key, increment := range increments {
+			cache, value, err := b.readCounter(txn, []byte(key), now)
+			if err != nil {
+				return err
+			}
+
+			value, err = addCounter(value, increment, math.MinInt64, math.MaxInt64)
+			if err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			cache.Data = []byte(strconv.FormatInt(value, 10))
+			data, err := b.encodeCache(cache)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set([]byte(key), data); err != nil {
+				return err
+			}
+			result[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Append 将data追加到key的值末尾，并返回追加后值的长度
+// key不存在时以data作为新值写入。读取与写入在同一个事务中完成，
+// 遇到并发冲突时自动重试，因此并发追加不会丢失数据
+// 示例：
+//
+//	n, err := db.Append("log", []byte("line\n"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) Append(key string, data []byte) (int, error) {
+	defer b.invalidate(key)
+
+	var n int
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var value []byte
+		item, err := txn.Get([]byte(key))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			if value, err = item.ValueCopy(nil); err != nil {
+				return err
+			}
+		}
+
+		value = append(value, data...)
+		if err := b.cfg.checkValueLen(value); err != nil {
+			return err
+		}
+		n = len(value)
+		return txn.Set([]byte(key), value)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// AppendS 将字符串追加到key的值末尾，并返回追加后值的长度
+// 示例：
+//
+//	n, err := db.AppendS("log", "line\n")
+func (b *BadgerDB) AppendS(key string, data string) (int, error) {
+	return b.Append(key, []byte(data))
+}
+
+// XAppend 将value追加到key存储的数据末尾，并返回追加后数据的长度
+// key原有的过期时间保持不变；key不存在或已过期时以value作为新数据写入，且不设置过期时间。
+// 读取与写入在同一个事务中完成，遇到并发冲突时自动重试
+// 示例：
+//
+//	n, err := db.XAppend("log:today", []byte("line\n"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XAppend(key string, value []byte) (int, error) {
+	defer b.invalidate(key)
+
+	var n int
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		now := time.Now().Unix()
+		cache := CacheType{Created: now}
+
+		item, err := txn.Get([]byte(key))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				old, err := b.decodeCache(val)
+				if err != nil {
+					return &CorruptEntryError{Key: key, Err: err}
+				}
+				if !old.expiredAt(now) {
+					cache = old
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		cache.Data = append(cache.Data, value...)
+		if err := b.cfg.checkValueLen(cache.Data); err != nil {
+			return err
+		}
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		n = len(cache.Data)
+		return txn.Set([]byte(key), data)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// readCounter 在事务中读取计数器的当前值，key不存在或已过期时视为0
+func (b *BadgerDB) readCounter(txn *badger.Txn, key []byte, now int64) (CacheType, int64, error) {
+	item, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return CacheType{Created: now}, 0, nil
+	}
+	if err != nil {
+		return CacheType{}, 0, err
+	}
+
+	var cache CacheType
+	var value int64
+	err = item.Value(func(val []byte) error {
+		cache, err = b.decodeCache(val)
+		if err != nil {
+			return err
+		}
+		if cache.expiredAt(now) {
+			cache = CacheType{Created: now}
+			return nil
+		}
+		value, err = strconv.ParseInt(string(cache.Data), 10, 64)
+		return err
+	})
+	if err != nil {
+		return CacheType{}, 0, err
+	}
+	return cache, value, nil
+}
+
+// Modify 在一个写事务中对key执行"读取-修改-写入"操作
+// fn 接收当前值及key是否存在，返回新值或通过delete要求删除该key；
+// 返回错误时放弃本次修改。遇到并发冲突时自动重试，因此fn可能被多次调用
+// 示例：
+//
+//	// 原子地追加数据
+//	err := db.Modify("log", func(old []byte, exists bool) ([]byte, bool, error) {
+//	    return append(old, []byte("line\n")...), false, nil
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) Modify(key string, fn func(old []byte, exists bool) (newVal []byte, delete bool, err error)) error {
+	defer b.invalidate(key)
+
+	var deleted bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var old []byte
+		exists := true
+		deleted = false
+
+		item, err := txn.Get([]byte(key))
+		if err == badger.ErrKeyNotFound {
+			exists = false
+		} else if err != nil {
+			return err
+		} else if old, err = item.ValueCopy(nil); err != nil {
+			return err
+		}
+
+		newVal, del, err := fn(old, exists)
+		if err != nil {
+			return err
+		}
+		if del {
+			if !exists {
+				return nil
+			}
+			deleted = true
+			return txn.Delete([]byte(key))
+		}
+		if err := b.cfg.checkValueLen(newVal); err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), newVal)
+	})
+	if err != nil {
+		return err
+	}
+	if deleted {
+		b.noteDeletes(1)
+	}
+	return nil
+}
+
+// XDecrAndDeleteAtZero 将key中存储的数字值减1，当结果小于等于0时删除该key
+// 返回减少后的值以及key是否被删除，减少与删除在同一个事务中完成，适用于引用计数
+// key不存在或已过期时返回 ErrNotFound，避免调用方误以为释放了最后一个引用；
+// 结果超出int64范围时返回 ErrIntegerOverflow，且不修改原值
+// 示例：
+//
+//	value, deleted, err := db.XDecrAndDeleteAtZero("ref:resource1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if deleted {
+//	    // 最后一个持有者已释放，清理资源
+//	}
+func (b *BadgerDB) XDecrAndDeleteAtZero(key string) (value int64, deleted bool, err error) {
+	defer b.invalidate(key)
+
+	err = b.updateWithRetry(func(txn *badger.Txn) error {
+		cache, current, err := b.readCounter(txn, []byte(key), time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		// readCounter 对不存在或已过期的key返回空的Data
+		if cache.Data == nil {
+			return badger.ErrKeyNotFound
+		}
+
+		value, err = addCounter(current, -1, math.MinInt64, math.MaxInt64)
+		if err != nil {
+			return err
+		}
+		deleted = value <= 0
+		if deleted {
+			return txn.Delete([]byte(key))
+		}
+
+		cache.Data = []byte(strconv.FormatInt(value, 10))
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
+	})
+	if err != nil {
+		return 0, false, notFound(key, err)
+	}
+	if deleted {
+		b.noteDeletes(1)
+	}
+	return value, deleted, nil
+}
+
+// addCounter 计算value+delta，溢出int64时返回 ErrIntegerOverflow，超出[min, max]时返回 ErrCounterOutOfRange
+func addCounter(value, delta, min, max int64) (int64, error) {
+	if (delta > 0 && value > math.MaxInt64-delta) || (delta < 0 && value < math.MinInt64-delta) {
+		return 0, fmt.Errorf("%w: %d%+d", ErrIntegerOverflow, value, delta)
+	}
+	sum := value + delta
+	if sum < min || sum > max {
+		return 0, fmt.Errorf("%w: %d not in [%d, %d]", ErrCounterOutOfRange, sum, min, max)
+	}
+	return sum, nil
+}
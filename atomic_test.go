@@ -0,0 +1,470 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestXIncrByMulti 测试XIncrByMulti方法
+func TestXIncrByMulti(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.XIncrBy("total", 5); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.XIncrByMulti(map[string]int64{"total": 1, "cat:a": 2}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	values, err := db.XIncrByMulti(map[string]int64{"total": 0, "cat:a": 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["total"] != 25 || values["cat:a"] != 40 {
+		t.Errorf("计数器结果不正确: %v", values)
+	}
+
+	// 非数字的值会导致整个事务失败，其他key不受影响
+	if err := db.XSetS("bad", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.XIncrByMulti(map[string]int64{"total": 1, "bad": 1}); err == nil {
+		t.Error("非数字的值应该返回错误")
+	}
+	if v, _ := db.XIncrBy("total", 0); v != 25 {
+		t.Errorf("失败的事务不应部分生效，total=%d", v)
+	}
+}
+
+// TestModify 测试Modify方法
+func TestModify(t *testing.T) {
+	db := newTestDB(t)
+
+	appendFn := func(old []byte, exists bool) ([]byte, bool, error) {
+		return append(old, 'x'), false, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := db.Modify("log", appendFn); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	val, err := db.GetS("log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != "xxxxxxxxxx" {
+		t.Errorf("并发追加结果不正确: %s", val)
+	}
+
+	err = db.Modify("log", func(old []byte, exists bool) ([]byte, bool, error) {
+		return nil, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Exists("log") {
+		t.Error("返回delete后key应被删除")
+	}
+}
+
+// TestXIncrByConcurrent 测试并发递增同一个计数器时不丢失更新
+func TestXIncrByConcurrent(t *testing.T) {
+	db := newTestDB(t)
+
+	const workers, perWorker = 8, 50
+	if err := db.XSetExS("hits", "0", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				if _, err := db.XIncr("hits"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, err := db.XGetS("hits"); err != nil || v != "400" {
+		t.Errorf("并发递增后的值不正确: %s, %v", v, err)
+	}
+	if ttl, _ := db.XTTL("hits"); ttl <= 0 {
+		t.Errorf("并发递增后应保留过期时间, 实际: %d", ttl)
+	}
+}
+
+// TestXDecrAndDeleteAtZero 测试XDecrAndDeleteAtZero方法
+func TestXDecrAndDeleteAtZero(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.XIncrBy("ref", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	value, deleted, err := db.XDecrAndDeleteAtZero("ref")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 1 || deleted {
+		t.Errorf("期望value=1且未删除，实际value=%d, deleted=%v", value, deleted)
+	}
+
+	value, deleted, err = db.XDecrAndDeleteAtZero("ref")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 0 || !deleted {
+		t.Errorf("期望value=0且已删除，实际value=%d, deleted=%v", value, deleted)
+	}
+	if db.Exists("ref") {
+		t.Error("计数归零后key应被删除")
+	}
+
+	if _, deleted, err := db.XDecrAndDeleteAtZero("ref"); !errors.Is(err, ErrNotFound) || deleted {
+		t.Errorf("不存在的key应返回ErrNotFound且未删除: %v, %v", deleted, err)
+	}
+
+	if _, err := db.XIncrBy("min", math.MinInt64); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := db.XDecrAndDeleteAtZero("min"); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("减少后溢出时应返回ErrIntegerOverflow: %v", err)
+	}
+	if v, _ := db.XGetS("min"); v != strconv.FormatInt(math.MinInt64, 10) {
+		t.Errorf("溢出时不应修改原值: %s", v)
+	}
+}
+
+// TestAtomicDeleteCounted 测试Modify和XDecrAndDeleteAtZero删除key时计入删除数量
+func TestAtomicDeleteCounted(t *testing.T) {
+	db := newTestDB(t)
+	db.cfg.deleteCompactionThreshold = 100
+
+	if err := db.SetS("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	err := db.Modify("k", func(old []byte, exists bool) ([]byte, bool, error) {
+		return nil, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 删除不存在的key不计数
+	err = db.Modify("k", func(old []byte, exists bool) ([]byte, bool, error) {
+		return nil, true, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := db.deletes.Load(); n != 1 {
+		t.Errorf("Modify删除后期望计数为1，实际为%d", n)
+	}
+
+	if _, err := db.XIncrBy("ref", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := db.XDecrAndDeleteAtZero("ref"); err != nil {
+		t.Fatal(err)
+	}
+	if n := db.deletes.Load(); n != 2 {
+		t.Errorf("XDecrAndDeleteAtZero删除后期望计数为2，实际为%d", n)
+	}
+}
+
+// TestXIncrByKeepTTL 测试递增带过期时间的计数器时保留过期时间
+func TestXIncrByKeepTTL(t *testing.T) {
+	db := newTestDB(t)
+
+	db.XSetExS("counter", "5", time.Hour)
+	if v, err := db.XIncrBy("counter", 1); err != nil || v != 6 {
+		t.Fatalf("递增结果不正确: %d, %v", v, err)
+	}
+	if ttl, _ := db.XTTL("counter"); ttl <= 0 {
+		t.Errorf("递增后应保留过期时间: %d", ttl)
+	}
+
+	// 已过期的计数器重新从0开始，且不再带有过期时间
+	db.XSetExSecS("expired", "5", 1)
+	time.Sleep(1100 * time.Millisecond)
+	if v, err := db.XIncrBy("expired", 1); err != nil || v != 1 {
+		t.Fatalf("已过期计数器递增结果不正确: %d, %v", v, err)
+	}
+	if ttl, _ := db.XTTL("expired"); ttl != -1 {
+		t.Errorf("已过期计数器递增后不应带有过期时间: %d", ttl)
+	}
+}
+
+// TestXAppend 测试XAppend方法
+func TestXAppend(t *testing.T) {
+	db := newTestDB(t)
+
+	if n, err := db.XAppend("log", []byte("a")); err != nil || n != 1 {
+		t.Fatalf("追加结果不正确: %d, %v", n, err)
+	}
+	if ttl, _ := db.XTTL("log"); ttl != -1 {
+		t.Errorf("新建的key不应带有过期时间: %d", ttl)
+	}
+
+	db.XSetExS("log", "ab", time.Hour)
+	if n, err := db.XAppend("log", []byte("c")); err != nil || n != 3 {
+		t.Fatalf("追加结果不正确: %d, %v", n, err)
+	}
+	if v, _ := db.XGetS("log"); v != "abc" {
+		t.Errorf("追加后的值不正确: %q", v)
+	}
+	if ttl, _ := db.XTTL("log"); ttl <= 0 {
+		t.Errorf("追加后应保留过期时间: %d", ttl)
+	}
+}
+
+// TestSetNX 测试并发SetNX只有一个成功
+func TestSetNX(t *testing.T) {
+	db := newTestDB(t)
+
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := db.SetNXS("lock", fmt.Sprintf("w%d", i))
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				atomic.AddInt32(&wins, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Errorf("期望只有1个调用方成功，实际为%d个", wins)
+	}
+	if ok, _ := db.SetNXS("lock", "again"); ok {
+		t.Error("key已存在时不应写入")
+	}
+}
+
+// TestGetSet 测试GetSet方法
+func TestGetSet(t *testing.T) {
+	db := newTestDB(t)
+
+	old, err := db.GetSet("k", []byte("v1"))
+	if err != nil || old != nil {
+		t.Errorf("key不存在时应返回nil: %q, %v", old, err)
+	}
+	if old, err := db.GetSetS("k", "v2"); err != nil || old != "v1" {
+		t.Errorf("应返回原有的值: %q, %v", old, err)
+	}
+	if v, _ := db.GetS("k"); v != "v2" {
+		t.Errorf("应写入新值: %q", v)
+	}
+}
+
+// TestCompareAndSwap 测试CompareAndSwap方法
+func TestCompareAndSwap(t *testing.T) {
+	db := newTestDB(t)
+
+	if ok, err := db.CompareAndSwap("k", nil, []byte("v")); err != nil || ok {
+		t.Errorf("key不存在时应返回false: %v, %v", ok, err)
+	}
+
+	db.SetS("k", "v1")
+	if ok, _ := db.CompareAndSwap("k", []byte("other"), []byte("v2")); ok {
+		t.Error("值不相等时不应写入")
+	}
+	if ok, err := db.CompareAndSwap("k", []byte("v1"), []byte("v2")); err != nil || !ok {
+		t.Errorf("值相等时应写入: %v, %v", ok, err)
+	}
+	if v, _ := db.GetS("k"); v != "v2" {
+		t.Errorf("写入后的值不正确: %q", v)
+	}
+
+	// 并发递增，每次交换成功才计数
+	db.SetS("n", "0")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				old, _ := db.Get("n")
+				n, _ := strconv.Atoi(string(old))
+				ok, err := db.CompareAndSwap("n", old, []byte(strconv.Itoa(n+1)))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if ok {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := db.GetS("n"); v != "10" {
+		t.Errorf("并发交换结果不正确: %s", v)
+	}
+}
+
+// TestAppend 测试并发Append不丢失数据
+func TestAppend(t *testing.T) {
+	db := newTestDB(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.AppendS("log", "x"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := db.GetS("log"); len(v) != 20 {
+		t.Errorf("期望长度20，实际为%d", len(v))
+	}
+	if n, err := db.AppendS("log", "yz"); err != nil || n != 22 {
+		t.Errorf("返回的长度不正确: %d, %v", n, err)
+	}
+}
+
+// TestXIncrByOverflow 测试计数器接近int64边界时的溢出检测
+func TestXIncrByOverflow(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.XIncrBy("max", math.MaxInt64-1); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.XIncrBy("max", 1); err != nil || v != math.MaxInt64 {
+		t.Fatalf("递增到上限结果不正确: %d, %v", v, err)
+	}
+	if _, err := db.XIncrBy("max", 1); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("超过上限时应返回ErrIntegerOverflow: %v", err)
+	}
+	if v, _ := db.XGetS("max"); v != strconv.FormatInt(math.MaxInt64, 10) {
+		t.Errorf("溢出时不应修改原值: %s", v)
+	}
+
+	if _, err := db.XIncrBy("min", math.MinInt64); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.XDecr("min"); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("低于下限时应返回ErrIntegerOverflow: %v", err)
+	}
+
+	if _, err := db.XIncrByMulti(map[string]int64{"ok": 1, "max": 1}); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("批量递增溢出时应返回ErrIntegerOverflow: %v", err)
+	}
+	if v, _ := db.XGet("ok"); v != nil {
+		t.Error("批量递增溢出时不应更新任何key")
+	}
+}
+
+// TestXIncrByWithLimit 测试带范围限制的递增
+func TestXIncrByWithLimit(t *testing.T) {
+	db := newTestDB(t)
+
+	if v, err := db.XIncrByWithLimit("stock", 3, 0, 5); err != nil || v != 3 {
+		t.Fatalf("递增结果不正确: %d, %v", v, err)
+	}
+	if _, err := db.XIncrByWithLimit("stock", 3, 0, 5); !errors.Is(err, ErrCounterOutOfRange) {
+		t.Errorf("超过上限时应返回ErrCounterOutOfRange: %v", err)
+	}
+	if _, err := db.XIncrByWithLimit("stock", -4, 0, 5); !errors.Is(err, ErrCounterOutOfRange) {
+		t.Errorf("低于下限时应返回ErrCounterOutOfRange: %v", err)
+	}
+	if v, _ := db.XGetS("stock"); v != "3" {
+		t.Errorf("超出范围时不应修改原值: %s", v)
+	}
+	if _, err := db.XIncrByWithLimit("stock", 1, 5, 0); err == nil {
+		t.Error("min大于max时应返回错误")
+	}
+}
+
+// TestGetDel 测试并发GetDel只有一个调用方取到值
+func TestGetDel(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetS("token", "abc"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wins int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := db.GetDelS("token")
+			if errors.Is(err, ErrNotFound) {
+				return
+			}
+			if err != nil || v != "abc" {
+				t.Errorf("GetDel返回值不正确: %q, %v", v, err)
+				return
+			}
+			atomic.AddInt32(&wins, 1)
+		}()
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Errorf("应只有一个调用方取到值, 实际: %d", wins)
+	}
+	if db.Exists("token") {
+		t.Error("GetDel后key应被删除")
+	}
+
+	if err := db.XSetExS("code", "1234", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.XGetDel("code"); err != nil || string(v) != "1234" {
+		t.Errorf("XGetDel返回值不正确: %q, %v", v, err)
+	}
+	if v, err := db.XGetDel("code"); err != nil || v != nil {
+		t.Errorf("key已删除时应返回nil: %q, %v", v, err)
+	}
+
+	if err := db.XSetExS("old", "x", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.XGetDel("old"); err != nil || v != nil {
+		t.Errorf("已过期的key应返回nil: %q, %v", v, err)
+	}
+	if db.Exists("old") {
+		t.Error("已过期的key应被删除")
+	}
+}
@@ -1,22 +1,53 @@
 package rbadger
 
 import (
-	"bytes"
-	"encoding/gob"
+	"context"
+	"errors"
+	"math"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
+	"golang.org/x/sync/singleflight"
 )
 
 // BadgerDB 结构体封装了 badger 的基本操作
 type BadgerDB struct {
 	db *badger.DB
-	mu sync.Mutex // 添加互斥锁
+
+	hookMu        sync.RWMutex
+	onExpire      func(key string)       // 过期key被删除时的回调
+	onReap        func(n int, err error) // 后台清理每轮结束时的回调
+	statsKeyCount bool                   // DumpStats 是否统计key数量
+
+	cfg config
+
+	workersMu     sync.Mutex
+	workers       map[string]*worker // 后台任务
+	workersClosed bool               // 为true时不再启动新的后台任务
+
+	rcache atomic.Pointer[readCache]     // 进程内读缓存，nil表示未启用
+	loads  singleflight.Group            // 合并同一key的并发加载
+	access atomic.Pointer[accessTracker] // 读取次数统计，nil表示未开启
+
+	deletes atomic.Int64 // 上次压缩以来删除的key数量
+
+	backupMu sync.RWMutex // 备份时持有读锁，DropAll 持有写锁
+
+	expired        chan string // 读取时发现的过期key，由后台任务批量删除
+	expiredIndexed chan string // 读取时发现的由 SetExIndexed 存储的过期key
+
+	countersMu sync.Mutex
+	counters   map[string]*Counter // 由 NewCounter 创建的计数器
+
+	versionedMu sync.Mutex               // ReplacePrefix 切换版本时持有
+	versioned   atomic.Pointer[[]string] // 已切换为版本化存储的前缀，nil表示没有
 }
 
 // NewBadgerDB 创建一个新的 BadgerDB 实例
+// 可以通过 Option 设置封装层的可选配置
 // 示例：
 //
 //	db, err := NewBadgerDB("./data")
@@ -24,13 +55,23 @@ type BadgerDB struct {
 //	    log.Fatal(err)
 //	}
 //	defer db.Close()
-func NewBadgerDB(dbPath string) (*BadgerDB, error) {
+func NewBadgerDB(dbPath string, options ...Option) (*BadgerDB, error) {
 	opts := badger.DefaultOptions(dbPath)
-	db, err := badger.Open(opts)
-	if err != nil {
-		return nil, err
-	}
-	return &BadgerDB{db: db, mu: sync.Mutex{}}, nil
+	return NewBadgerDBWithOptions(opts, options...)
+}
+
+// NewBadgerDBSplitDirs 创建一个 LSM 与值日志分别存放在不同目录的 BadgerDB 实例
+// 适合将 LSM 放在高速磁盘上、值日志放在大容量磁盘上
+// 示例：
+//
+//	db, err := NewBadgerDBSplitDirs("/ssd/data", "/hdd/vlog")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer db.Close()
+func NewBadgerDBSplitDirs(lsmDir, valueDir string, options ...Option) (*BadgerDB, error) {
+	opts := badger.DefaultOptions(lsmDir).WithValueDir(valueDir)
+	return NewBadgerDBWithOptions(opts, options...)
 }
 
 // NewBadgerDBWithOptions 创建一个带自定义选项的 BadgerDB 实例
@@ -43,15 +84,31 @@ func NewBadgerDB(dbPath string) (*BadgerDB, error) {
 //	    log.Fatal(err)
 //	}
 //	defer db.Close()
-func NewBadgerDBWithOptions(opts badger.Options) (*BadgerDB, error) {
+func NewBadgerDBWithOptions(opts badger.Options, options ...Option) (*BadgerDB, error) {
+	cfg := newConfig(options)
+	if cfg.numCompactors > 0 {
+		opts = opts.WithNumCompactors(cfg.numCompactors)
+	}
+
 	db, err := badger.Open(opts)
 	if err != nil {
 		return nil, err
 	}
-	return &BadgerDB{db: db, mu: sync.Mutex{}}, nil
+	b := &BadgerDB{
+		db:             db,
+		cfg:            cfg,
+		expired:        make(chan string, expireQueueSize),
+		expiredIndexed: make(chan string, expireQueueSize),
+	}
+	if err := b.loadVersionedPrefixes(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
 }
 
 // Get 获取指定key的值
+// key不存在时返回 ErrNotFound，该错误同时匹配 badger.ErrKeyNotFound
 // 示例：
 //
 //	value, err := db.Get("key")
@@ -60,12 +117,36 @@ func NewBadgerDBWithOptions(opts badger.Options) (*BadgerDB, error) {
 //	}
 //	fmt.Printf("值: %s\n", value)
 func (b *BadgerDB) Get(key string) ([]byte, error) {
+	return b.GetBytes([]byte(key))
+}
+
+// GetBytes 获取指定二进制key的值
+// 示例：
+//
+//	value, err := db.GetBytes([]byte{0x01, 0x00, 0xff})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetBytes(key []byte) ([]byte, error) {
+	b.trackAccess(key)
+
+	cached, gen, ok := b.cacheGet(key)
+	if ok {
+		return cached, nil
+	}
+
 	var valCopy []byte
+	var expiresAt uint64
 	err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(key))
+		physical, err := b.resolveKey(txn, key)
 		if err != nil {
 			return err
 		}
+		item, err := txn.Get(physical)
+		if err != nil {
+			return err
+		}
+		expiresAt = item.ExpiresAt()
 
 		err = item.Value(func(val []byte) error {
 			// 复制值，因为在事务外部使用值需要复制
@@ -74,7 +155,11 @@ func (b *BadgerDB) Get(key string) ([]byte, error) {
 		})
 		return err
 	})
-	return valCopy, err
+	// 由 badger 处理过期的key不放入读缓存，避免过期后仍从缓存返回
+	if err == nil && expiresAt == 0 {
+		b.cacheAdd(key, valCopy, gen)
+	}
+	return valCopy, notFound(string(key), err)
 }
 
 // GetS 获取指定key的字符串值
@@ -101,8 +186,23 @@ func (b *BadgerDB) GetS(key string) (string, error) {
 //	    log.Fatal(err)
 //	}
 func (b *BadgerDB) Set(key string, value []byte) error {
+	return b.SetBytes([]byte(key), value)
+}
+
+// SetBytes 设置二进制key的值
+// 示例：
+//
+//	err := db.SetBytes([]byte{0x01, 0x00, 0xff}, []byte("value"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetBytes(key, value []byte) error {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(string(key))
 	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), value)
+		return txn.Set(key, value)
 	})
 }
 
@@ -118,6 +218,8 @@ func (b *BadgerDB) SetS(key string, value string) error {
 }
 
 // Exists 检查key是否存在
+// 只检查key是否存储在数据库中，不解析值：带过期时间存储的数据在逻辑上过期但尚未被删除时仍返回true，
+// 需要与 XGet 的结果保持一致时应使用 XExists
 // 示例：
 //
 //	if db.Exists("key") {
@@ -126,8 +228,22 @@ func (b *BadgerDB) SetS(key string, value string) error {
 //	    fmt.Println("key不存在")
 //	}
 func (b *BadgerDB) Exists(key string) bool {
+	return b.ExistsBytes([]byte(key))
+}
+
+// ExistsBytes 检查二进制key是否存在
+// 示例：
+//
+//	if db.ExistsBytes([]byte{0x01, 0x00, 0xff}) {
+//	    fmt.Println("key存在")
+//	}
+func (b *BadgerDB) ExistsBytes(key []byte) bool {
 	err := b.db.View(func(txn *badger.Txn) error {
-		_, err := txn.Get([]byte(key))
+		physical, err := b.resolveKey(txn, key)
+		if err != nil {
+			return err
+		}
+		_, err = txn.Get(physical)
 		return err
 	})
 	return err == nil
@@ -141,16 +257,56 @@ func (b *BadgerDB) Exists(key string) bool {
 //	    log.Fatal(err)
 //	}
 func (b *BadgerDB) Del(key string) error {
-	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Delete([]byte(key))
+	return b.DelBytes([]byte(key))
+}
+
+// DelBytes 删除指定的二进制key
+// 示例：
+//
+//	err := db.DelBytes([]byte{0x01, 0x00, 0xff})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DelBytes(key []byte) error {
+	defer b.invalidate(string(key))
+	err := b.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
 	})
+	if err == nil {
+		b.noteDeletes(1)
+	}
+	return err
 }
 
-// Close 关闭数据库连接
+// Close 停止所有后台任务和计数器的合并任务，并关闭数据库连接
 // 示例：
 //
 //	defer db.Close()
 func (b *BadgerDB) Close() error {
+	b.stopWorkers()
+	b.stopCounters()
+	return b.db.Close()
+}
+
+// Shutdown 优雅关闭数据库
+// 通知所有后台任务（垃圾回收等）停止并等待其退出，随后执行一次磁盘同步再关闭数据库。
+// ctx结束前后台任务仍未退出时，跳过同步直接关闭数据库，并返回ctx的错误
+// 示例：
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := db.Shutdown(ctx); err != nil {
+//	    log.Printf("关闭数据库: %v", err)
+//	}
+func (b *BadgerDB) Shutdown(ctx context.Context) error {
+	err := b.stopWorkersCtx(ctx)
+	b.stopCounters()
+	if err != nil {
+		return errors.Join(err, b.db.Close())
+	}
+	if err := b.db.Sync(); err != nil {
+		return errors.Join(err, b.db.Close())
+	}
 	return b.db.Close()
 }
 
@@ -158,12 +314,17 @@ func (b *BadgerDB) Close() error {
 
 // CacheType 定义缓存数据结构
 type CacheType struct {
-	Data   []byte
-	Expire int64 // Unix timestamp 表示过期时间点
+	Data    []byte
+	Expire  int64 // Unix timestamp 表示过期时间点
+	Created int64 // Unix timestamp 表示写入时间点，旧数据中为0
+
+	Sliding    bool  `json:",omitempty"` // 为true时每次 XGet 读取都会将过期时间顺延TTLSeconds
+	TTLSeconds int64 `json:",omitempty"` // 滑动过期的时长(秒)
 }
 
 // XGet 获取带过期时间的缓存数据
-// 当数据过期时会自动删除并返回nil
+// 数据过期时返回nil，过期的key由后台任务批量删除，读取本身不产生写事务；
+// 通过 XSetSliding 写入的key例外，读取时会在写事务中顺延其过期时间
 // 示例：
 //
 //	value, err := db.XGet("key")
@@ -176,18 +337,35 @@ type CacheType struct {
 //	    fmt.Printf("值: %s\n", value)
 //	}
 func (b *BadgerDB) XGet(key string) ([]byte, error) {
+	value, _, err := b.XGetWithFound(key)
+	return value, err
+}
+
+// XGetWithFound 获取带过期时间的缓存数据，并返回key是否存在
+// found 仅在key不存在或已过期时为false，存储的数据为空时found为true、value为空切片，
+// 因此可以区分缓存未命中与缓存了空值。已过期的数据由后台任务异步删除
+// 示例：
+//
+//	value, found, err := db.XGetWithFound("key")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !found {
+//	    misses.Inc()
+//	}
+func (b *BadgerDB) XGetWithFound(key string) (value []byte, found bool, err error) {
 	var valCopy []byte
-	err := b.db.View(func(txn *badger.Txn) error {
+	var sliding bool
+	err = b.db.View(func(txn *badger.Txn) error {
 		item, err := txn.Get([]byte(key))
 		if err != nil {
 			return err
 		}
 
 		return item.Value(func(val []byte) error {
-			var cache CacheType
-			decoder := gob.NewDecoder(bytes.NewReader(val))
-			if err := decoder.Decode(&cache); err != nil {
-				return err
+			cache, err := b.decodeCache(val)
+			if err != nil {
+				return &CorruptEntryError{Key: key, Err: err}
 			}
 
 			// 检查是否过期
@@ -198,21 +376,27 @@ func (b *BadgerDB) XGet(key string) ([]byte, error) {
 
 			// 复制值，因为在事务外部使用值需要复制
 			valCopy = append([]byte{}, cache.Data...)
+			sliding = cache.sliding()
 			return nil
 		})
 	})
 
+	if err == nil && sliding {
+		value, err = b.xgetAndSetExpire(key, slideExpire)
+		return value, value != nil, err
+	}
+
 	if err == badger.ErrKeyNotFound {
 		// 如果是过期或不存在，尝试删除（如果是过期的情况）
-		b.Del(key)
-		return nil, nil
+		b.expireKeys(key)
+		return nil, false, nil
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, false, b.handleCorrupt(key, err)
 	}
 
-	return valCopy, nil
+	return valCopy, true, nil
 }
 
 // XGetS 获取带过期时间的字符串数据
@@ -238,6 +422,39 @@ func (b *BadgerDB) XGetS(key string) (string, error) {
 	return string(data), nil
 }
 
+// XExists 检查带过期时间存储的key是否存在且未过期，结果与 XGet 是否返回nil一致
+// 值无法解码为缓存数据时返回false；已过期的key会交给后台任务删除
+// 示例：
+//
+//	if db.XExists("session:1") {
+//	    fmt.Println("会话有效")
+//	}
+func (b *BadgerDB) XExists(key string) bool {
+	var expired bool
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			cache, err := b.decodeCache(val)
+			if err != nil {
+				return err
+			}
+			expired = cache.expiredAt(time.Now().Unix())
+			return nil
+		})
+	})
+	if err != nil {
+		return false
+	}
+	if expired {
+		b.expireKeys(key)
+		return false
+	}
+	return true
+}
+
 // XSet 设置带过期时间的缓存数据
 // 示例：
 //
@@ -246,19 +463,24 @@ func (b *BadgerDB) XGetS(key string) (string, error) {
 //	    log.Fatal(err)
 //	}
 func (b *BadgerDB) XSet(key string, value []byte) error {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(key)
+
 	cache := CacheType{
-		Data:   value,
-		Expire: 0,
+		Data:    value,
+		Expire:  0,
+		Created: time.Now().Unix(),
 	}
 
-	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(cache); err != nil {
+	data, err := b.encodeCache(cache)
+	if err != nil {
 		return err
 	}
 
 	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), buf.Bytes())
+		return txn.Set([]byte(key), data)
 	})
 }
 
@@ -281,19 +503,64 @@ func (b *BadgerDB) XSetS(key string, value string) error {
 //	    log.Fatal(err)
 //	}
 func (b *BadgerDB) XSetEx(key string, value []byte, expires time.Duration) error {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(key)
+
+	now := time.Now()
 	cache := CacheType{
-		Data:   value,
-		Expire: time.Now().Add(expires).Unix(),
+		Data:    value,
+		Expire:  now.Add(expires).Unix(),
+		Created: now.Unix(),
 	}
 
-	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(cache); err != nil {
+	data, err := b.encodeCache(cache)
+	if err != nil {
 		return err
 	}
 
 	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), buf.Bytes())
+		return txn.Set([]byte(key), data)
+	})
+}
+
+// XSetSliding 设置滑动过期的缓存数据，过期时间为当前时间加上ttl
+// 之后每次通过 XGet 读取时都会将过期时间重新设置为读取时间加上ttl，
+// 因此频繁访问的key不会过期，闲置超过ttl的key才会过期。
+// 注意：读取滑动过期的key会额外产生一次写事务（同一秒内的重复读取不会重复写入）；
+// 只有通过本方法写入的key才会滑动过期，XSet/XSetEx 写入的key不受影响
+// 示例：
+//
+//	err := db.XSetSliding("session:1", data, 30*time.Minute)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XSetSliding(key string, value []byte, ttl time.Duration) error {
+	if ttl < time.Second {
+		return errors.New("rbadger: sliding ttl must be at least one second")
+	}
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(key)
+
+	now := time.Now()
+	cache := CacheType{
+		Data:       value,
+		Expire:     now.Add(ttl).Unix(),
+		Created:    now.Unix(),
+		Sliding:    true,
+		TTLSeconds: int64(ttl / time.Second),
+	}
+
+	data, err := b.encodeCache(cache)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), data)
 	})
 }
 
@@ -361,10 +628,9 @@ func (b *BadgerDB) XTTL(key string) (int64, error) {
 		}
 
 		return item.Value(func(val []byte) error {
-			var cache CacheType
-			decoder := gob.NewDecoder(bytes.NewReader(val))
-			if err := decoder.Decode(&cache); err != nil {
-				return err
+			cache, err := b.decodeCache(val)
+			if err != nil {
+				return &CorruptEntryError{Key: key, Err: err}
 			}
 
 			// key 存在但未设置过期时间
@@ -388,12 +654,12 @@ func (b *BadgerDB) XTTL(key string) (int64, error) {
 
 	if err == badger.ErrKeyNotFound {
 		// 如果是过期或不存在，尝试删除（如果是过期的情况）
-		b.Del(key)
+		b.expireKeys(key)
 		return -2, nil
 	}
 
 	if err != nil {
-		return -2, err
+		return -2, b.handleCorrupt(key, err)
 	}
 
 	return ttl, nil
@@ -430,45 +696,38 @@ func (b *BadgerDB) XExpireSec(key string, seconds int64) error {
 //	    log.Fatal(err)
 //	}
 func (b *BadgerDB) XExpireAt(key string, tm time.Time) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	defer b.invalidate(key)
 
-	var cache CacheType
-
-	// 先获取当前值
-	err := b.db.View(func(txn *badger.Txn) error {
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
 		item, err := txn.Get([]byte(key))
 		if err != nil {
 			return err
 		}
 
-		return item.Value(func(val []byte) error {
-			decoder := gob.NewDecoder(bytes.NewReader(val))
-			return decoder.Decode(&cache)
+		var cache CacheType
+		err = item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			return err
 		})
-	})
-
-	if err != nil {
-		return err
-	}
-
-	// 设置新的过期时间
-	cache.Expire = tm.Unix()
-
-	// 保存回数据库
-	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(cache); err != nil {
-		return err
-	}
+		if err != nil {
+			return err
+		}
 
-	return b.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), buf.Bytes())
+		// 设置新的过期时间
+		cache.Expire = tm.Unix()
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
 	})
+	return notFound(key, err)
 }
 
 // XIncrBy 将key中存储的数字值增加指定的值
-// 该方法是并发安全的
+// 该方法是并发安全的。key原有的过期时间保持不变，带过期时间的计数器不会因递增而变为永不过期；
+// key不存在或已过期时从0开始计数，且不设置过期时间。
+// 结果超出int64范围时返回 ErrIntegerOverflow，且不修改原值
 // 示例：
 //
 //	value, err := db.XIncrBy("counter", 10)
@@ -477,62 +736,53 @@ func (b *BadgerDB) XExpireAt(key string, tm time.Time) error {
 //	}
 //	fmt.Printf("新值: %d\n", value)
 func (b *BadgerDB) XIncrBy(key string, increment int64) (int64, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	return b.xincrBy(key, increment, math.MinInt64, math.MaxInt64)
+}
 
-	var cache CacheType
-	var value int64
+// XIncrByWithLimit 与 XIncrBy 相同，但结果必须位于[min, max]范围内
+// 结果超出范围时返回 ErrCounterOutOfRange，超出int64范围时返回 ErrIntegerOverflow，两种情况都不修改原值
+// 示例：
+//
+//	// 库存不能为负数，也不能超过仓库容量
+//	stock, err := db.XIncrByWithLimit("stock:sku1", -2, 0, 1000)
+//	if errors.Is(err, rbadger.ErrCounterOutOfRange) {
+//	    fmt.Println("库存不足")
+//	}
+func (b *BadgerDB) XIncrByWithLimit(key string, delta, min, max int64) (int64, error) {
+	if min > max {
+		return 0, errors.New("rbadger: min must not be greater than max")
+	}
+	return b.xincrBy(key, delta, min, max)
+}
 
-	// 先获取当前值
-	err := b.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get([]byte(key))
-		if err == badger.ErrKeyNotFound {
-			// key不存在，初始化为0
-			cache = CacheType{
-				Expire: 0,
-			}
-			value = 0
-			return nil
-		}
+// xincrBy 将计数器增加increment，结果必须位于[min, max]范围内
+// 读取与写入在同一个事务中完成，依赖badger的冲突检测保证并发递增不丢失，遇到冲突时自动重试
+func (b *BadgerDB) xincrBy(key string, increment, min, max int64) (int64, error) {
+	defer b.invalidate(key)
+
+	var value int64
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		// 已过期的计数器视为不存在，重新从0开始（cache.Expire 保持不变）
+		cache, current, err := b.readCounter(txn, []byte(key), time.Now().Unix())
 		if err != nil {
 			return err
 		}
 
-		return item.Value(func(val []byte) error {
-			decoder := gob.NewDecoder(bytes.NewReader(val))
-			if err := decoder.Decode(&cache); err != nil {
-				return err
-			}
-
-			// 解析当前值
-			value, err = strconv.ParseInt(string(cache.Data), 10, 64)
+		value, err = addCounter(current, increment, min, max)
+		if err != nil {
 			return err
-		})
-	})
-
-	if err != nil {
-		return 0, err
-	}
-
-	// 增加值
-	value += increment
-	cache.Data = []byte(strconv.FormatInt(value, 10))
-
-	// 保存新值
-	var buf bytes.Buffer
-	encoder := gob.NewEncoder(&buf)
-	if err := encoder.Encode(cache); err != nil {
-		return 0, err
-	}
+		}
+		cache.Data = []byte(strconv.FormatInt(value, 10))
 
-	err = b.db.Update(func(txn *badger.Txn) error {
-		return txn.Set([]byte(key), buf.Bytes())
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
 	})
-
 	if err != nil {
 		return 0, err
 	}
-
 	return value, nil
 }
 
@@ -597,6 +847,7 @@ func (b *BadgerDB) RunGC(discardRatio float64) error {
 
 // FindKeys 扫描所有匹配指定前缀的key列表
 // 返回所有匹配前缀的key，包括普通存储和带过期时间存储的key
+// 通过 WithMaxResultBytes 设置上限后，结果超过上限时返回 ErrResultTooLarge
 // 示例：
 //
 //	keys, err := db.FindKeys("user:")
@@ -608,6 +859,7 @@ func (b *BadgerDB) RunGC(discardRatio float64) error {
 //	}
 func (b *BadgerDB) FindKeys(prefix string) ([]string, error) {
 	var keys []string
+	var size int
 	err := b.db.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.PrefetchValues = false // 只需要key，不需要预取值
@@ -617,12 +869,22 @@ func (b *BadgerDB) FindKeys(prefix string) ([]string, error) {
 		prefixBytes := []byte(prefix)
 		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
 			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
 			key := string(item.Key())
+			size += len(key)
+			if b.cfg.maxResultBytes > 0 && size > b.cfg.maxResultBytes {
+				return ErrResultTooLarge
+			}
 			keys = append(keys, key)
 		}
 		return nil
 	})
-	return keys, err
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
 }
 
 // FindXKeys 扫描所有匹配指定前缀且未过期的key列表
@@ -648,13 +910,15 @@ func (b *BadgerDB) FindXKeys(prefix string) ([]string, error) {
 		prefixBytes := []byte(prefix)
 		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
 			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
 			key := string(item.Key())
 
 			// 尝试解析值以检查是否为CacheType且是否过期
 			err := item.Value(func(val []byte) error {
-				var cache CacheType
-				decoder := gob.NewDecoder(bytes.NewReader(val))
-				if err := decoder.Decode(&cache); err != nil {
+				cache, err := b.decodeCache(val)
+				if err != nil {
 					// 如果无法解码为CacheType，跳过此key
 					return nil
 				}
@@ -682,9 +946,79 @@ func (b *BadgerDB) FindXKeys(prefix string) ([]string, error) {
 	}
 
 	// 删除已过期的key
-	for _, expiredKey := range expiredKeys {
-		b.Del(expiredKey)
-	}
+	b.expireKeys(expiredKeys...)
 
 	return keys, nil
 }
+
+// CountKeys 统计匹配指定前缀的key数量
+// 只遍历key而不读取值，也不会保存key，适合统计大量数据
+// 示例：
+//
+//	n, err := db.CountKeys("user:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("共%d个用户\n", n)
+func (b *BadgerDB) CountKeys(prefix string) (int64, error) {
+	var count int64
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false // 只需要key，不需要预取值
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefixBytes := []byte(prefix)
+		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
+			if !isReservedKey(it.Item().Key()) {
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountXKeys 统计匹配指定前缀且未过期的带过期时间存储的key数量
+// 需要读取值以判断是否过期，但不会保存key；与 FindXKeys 不同，不会删除已过期的key
+// 示例：
+//
+//	n, err := db.CountXKeys("cache:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) CountXKeys(prefix string) (int64, error) {
+	var count int64
+	now := time.Now().Unix()
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefixBytes := []byte(prefix)
+		for it.Seek(prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err == nil && !cache.expiredAt(now) {
+					count++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -1,8 +1,10 @@
 package rbadger
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -148,3 +150,140 @@ func TestInMemory(t *testing.T) {
 	db.SetS("key", "value")
 	t.Log(db.GetS("key"))
 }
+
+// TestBytesKeys 测试二进制key的读写
+func TestBytesKeys(t *testing.T) {
+	db := newTestDB(t)
+
+	key := []byte{0x01, 0x00, 0xff}
+	if err := db.SetBytes(key, []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if !db.ExistsBytes(key) {
+		t.Fatal("二进制key应该存在")
+	}
+
+	val, err := db.GetBytes(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "value" {
+		t.Errorf("值不正确: %s", val)
+	}
+
+	// 字符串方法与二进制方法访问的是同一个key
+	if s, _ := db.GetS(string(key)); s != "value" {
+		t.Errorf("通过字符串key读取的值不正确: %s", s)
+	}
+
+	if err := db.DelBytes(key); err != nil {
+		t.Fatal(err)
+	}
+	if db.ExistsBytes(key) {
+		t.Error("二进制key应该已被删除")
+	}
+}
+
+// TestMaxValueLen 测试写入值的长度上限
+func TestMaxValueLen(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts, WithMaxValueLen(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.SetS("k", "1234"); err != nil {
+		t.Errorf("未超过上限时应写入成功: %v", err)
+	}
+	if err := db.SetS("k", "12345"); !errors.Is(err, ErrValueTooLong) {
+		t.Errorf("SetS应返回ErrValueTooLong: %v", err)
+	}
+	if err := db.XSetS("x", "12345"); !errors.Is(err, ErrValueTooLong) {
+		t.Errorf("XSetS应返回ErrValueTooLong: %v", err)
+	}
+	if err := db.XSetExS("x", "12345", time.Minute); !errors.Is(err, ErrValueTooLong) {
+		t.Errorf("XSetExS应返回ErrValueTooLong: %v", err)
+	}
+	if v, _ := db.GetS("k"); v != "1234" {
+		t.Errorf("超长的值不应写入: %q", v)
+	}
+
+	long := []byte("12345")
+	kvs := map[string][]byte{"k": long}
+	writes := map[string]func() error{
+		"SetManyAndSync": func() error { return db.SetManyAndSync(kvs) },
+		"XMSetKeepTTL":   func() error { return db.XMSetKeepTTL(kvs) },
+		"ReplacePrefix":  func() error { return db.ReplacePrefix("k", kvs) },
+		"SetExIndexed":   func() error { return db.SetExIndexed("k", long, time.Minute) },
+		"SetIfVersion":   func() error { _, err := db.SetIfVersion("k", long, 0); return err },
+		"Modify": func() error {
+			return db.Modify("k", func(old []byte, exists bool) ([]byte, bool, error) {
+				return long, false, nil
+			})
+		},
+		"SetStruct": func() error {
+			return db.SetStruct("s", struct{ Name string }{Name: "12345"})
+		},
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, ErrValueTooLong) {
+			t.Errorf("%s应返回ErrValueTooLong: %v", name, err)
+		}
+	}
+	if v, _ := db.GetS("k"); v != "1234" {
+		t.Errorf("超长的值不应写入: %q", v)
+	}
+}
+
+// TestSplitDirs 测试LSM与值日志分目录存放
+func TestSplitDirs(t *testing.T) {
+	lsmDir, valueDir := t.TempDir(), t.TempDir()
+
+	db, err := NewBadgerDBSplitDirs(lsmDir, valueDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetS("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	vlogs, _ := filepath.Glob(filepath.Join(valueDir, "*.vlog"))
+	if len(vlogs) == 0 {
+		t.Error("值日志应位于valueDir中")
+	}
+	if vlogs, _ := filepath.Glob(filepath.Join(lsmDir, "*.vlog")); len(vlogs) != 0 {
+		t.Error("lsmDir中不应有值日志")
+	}
+}
+
+// TestErrNotFound 测试各读取方法在key不存在时返回ErrNotFound，并兼容badger.ErrKeyNotFound
+func TestErrNotFound(t *testing.T) {
+	db := newTestDB(t)
+
+	checks := map[string]error{}
+	_, checks["Get"] = db.Get("missing")
+	_, checks["GetS"] = db.GetS("missing")
+	_, checks["GetBytes"] = db.GetBytes([]byte("missing"))
+	_, _, checks["GetWithVersion"] = db.GetWithVersion("missing")
+	_, checks["GetLarge"] = db.GetLarge("missing")
+	_, checks["GetMeta"] = db.GetMeta("missing")
+	checks["GetJSON"] = db.GetJSON("missing", &struct{}{})
+	checks["XExpire"] = db.XExpire("missing", time.Hour)
+	checks["ExpireIndexed"] = db.ExpireIndexed("missing", time.Hour)
+	checks["GCKey"] = db.GCKey("missing")
+
+	for name, err := range checks {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s 应返回ErrNotFound: %v", name, err)
+		}
+		if !errors.Is(err, badger.ErrKeyNotFound) {
+			t.Errorf("%s 返回的错误应兼容badger.ErrKeyNotFound: %v", name, err)
+		}
+	}
+
+	if _, err := db.Get("missing"); err.Error() != `rbadger: key not found: "missing"` {
+		t.Errorf("错误信息应包含key: %v", err)
+	}
+}
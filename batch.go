@@ -0,0 +1,501 @@
+package rbadger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// MGet 在一个只读事务中获取多个key的值
+// 不存在的key不会出现在返回结果中
+// 示例：
+//
+//	values, err := db.MGet([]string{"k1", "k2", "k3"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for key, value := range values {
+//	    fmt.Printf("%s: %s\n", key, value)
+//	}
+func (b *BadgerDB) MGet(keys []string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(keys))
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get([]byte(key))
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			val, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			result[key] = val
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MGetS 在一个只读事务中获取多个key的字符串值
+// 不存在的key不会出现在返回结果中
+// 示例：
+//
+//	values, err := db.MGetS([]string{"k1", "k2"})
+func (b *BadgerDB) MGetS(keys []string) (map[string]string, error) {
+	values, err := b.MGet(keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = string(value)
+	}
+	return result, nil
+}
+
+// MSet 在一个事务中写入多个key，所有key要么全部写入成功，要么全部不写入
+// 数据量超过badger的事务上限时返回 badger.ErrTxnTooBig，此时不会写入任何数据
+// 示例：
+//
+//	err := db.MSet(map[string][]byte{
+//	    "k1": []byte("v1"),
+//	    "k2": []byte("v2"),
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) MSet(pairs map[string][]byte) error {
+	if err := b.cfg.checkValueLens(pairs); err != nil {
+		return err
+	}
+	defer b.purgeReadCache()
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		for key, value := range pairs {
+			if err := txn.Set([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MSetS 在一个事务中写入多个字符串值，所有key要么全部写入成功，要么全部不写入
+// 示例：
+//
+//	err := db.MSetS(map[string]string{"k1": "v1", "k2": "v2"})
+func (b *BadgerDB) MSetS(pairs map[string]string) error {
+	kvs := make(map[string][]byte, len(pairs))
+	for key, value := range pairs {
+		kvs[key] = []byte(value)
+	}
+	return b.MSet(kvs)
+}
+
+// GetManyCtx 使用有限数量的goroutine并发获取多个key的值
+// 每个goroutine使用独立的只读事务，不存在的key不会出现在结果中。
+// 当ctx被取消时停止获取，并返回已获取的部分结果及ctx的错误
+// 示例：
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	values, err := db.GetManyCtx(ctx, keys, 8)
+//	if err != nil {
+//	    log.Printf("获取中断: %v，已获取%d个", err, len(values))
+//	}
+func (b *BadgerDB) GetManyCtx(ctx context.Context, keys []string, workers int) (map[string][]byte, error) {
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(keys) {
+		workers = len(keys)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		result   = make(map[string][]byte, len(keys))
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			txn := b.db.NewTransaction(false)
+			defer txn.Discard()
+
+			for key := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				item, err := txn.Get([]byte(key))
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				var val []byte
+				if err == nil {
+					val, err = item.ValueCopy(nil)
+				}
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					result[key] = val
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, key := range keys {
+		select {
+		case jobs <- key:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return result, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// XMGetPartition 在一个只读事务中读取多个带过期时间的key，并按命中情况划分
+// hits 包含未过期的key及其值，misses 包含不存在或已过期的key（按传入顺序），
+// 已过期的key会在读取完成后交给后台任务删除
+// 示例：
+//
+//	hits, misses, err := db.XMGetPartition([]string{"user:1", "user:2"})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, key := range misses {
+//	    // 从数据源加载缺失的数据
+//	}
+func (b *BadgerDB) XMGetPartition(keys []string) (hits map[string][]byte, misses []string, err error) {
+	hits = make(map[string][]byte, len(keys))
+	var expiredKeys []string
+
+	now := time.Now().Unix()
+	err = b.db.View(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get([]byte(key))
+			if err == badger.ErrKeyNotFound {
+				misses = append(misses, key)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err != nil {
+					return err
+				}
+				if cache.expiredAt(now) {
+					misses = append(misses, key)
+					expiredKeys = append(expiredKeys, key)
+					return nil
+				}
+				hits[key] = append([]byte{}, cache.Data...)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b.expireKeys(expiredKeys...)
+	return hits, misses, nil
+}
+
+// SetManyAndSync 批量写入多个key，并在全部写入后执行一次磁盘同步
+// 返回nil时所有数据均已持久化，适合为一组相关数据建立持久化检查点
+// 示例：
+//
+//	err := db.SetManyAndSync(map[string][]byte{
+//	    "order:1": []byte("..."),
+//	    "order:2": []byte("..."),
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetManyAndSync(kvs map[string][]byte) error {
+	if err := b.cfg.checkValueLens(kvs); err != nil {
+		return err
+	}
+	defer b.purgeReadCache()
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for key, value := range kvs {
+		if err := wb.Set([]byte(key), value); err != nil {
+			return err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+	return b.db.Sync()
+}
+
+// XSetKeepTTL 更新带过期时间的缓存数据，保留key原有的过期时间
+// key不存在或已过期时视为永不过期
+// 示例：
+//
+//	err := db.XSetKeepTTL("key", []byte("new value"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XSetKeepTTL(key string, value []byte) error {
+	return b.XMSetKeepTTL(map[string][]byte{key: value})
+}
+
+// XMSetKeepTTL 批量更新带过期时间的缓存数据，每个key保留其原有的过期时间、写入时间和滑动过期设置
+// key不存在或已过期时视为永不过期。读取原过期时间与写入新值在同一个事务中完成，
+// 遇到并发冲突时自动重试；key较多时按批拆分为多个事务
+// 示例：
+//
+//	err := db.XMSetKeepTTL(map[string][]byte{
+//	    "user:1": []byte("..."),
+//	    "user:2": []byte("..."),
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XMSetKeepTTL(kvs map[string][]byte) error {
+	if err := b.cfg.checkValueLens(kvs); err != nil {
+		return err
+	}
+	defer b.purgeReadCache()
+
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+
+	for start := 0; start < len(keys); start += expireBatchSize {
+		end := start + expireBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			now := time.Now().Unix()
+			for _, key := range keys[start:end] {
+				cache := CacheType{Created: now}
+				item, err := txn.Get([]byte(key))
+				if err != nil && err != badger.ErrKeyNotFound {
+					return err
+				}
+				if err == nil {
+					err = item.Value(func(val []byte) error {
+						old, err := b.decodeCache(val)
+						if err != nil {
+							return err
+						}
+						// 保留原有的过期时间、写入时间与滑动过期设置，只替换数据
+						if !old.expiredAt(now) {
+							cache = old
+						}
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+				}
+				cache.Data = kvs[key]
+
+				data, err := b.encodeCache(cache)
+				if err != nil {
+					return err
+				}
+				if err := txn.Set([]byte(key), data); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplacePrefix 将指定前缀下的内容整体替换为kvs
+// 前缀下不在kvs中的key会被删除，kvs中的key全部写入，读取方只会看到替换前或替换后的完整状态。
+// kvs中的key必须以prefix开头，否则返回 ErrKeyOutsidePrefix。
+// 数据量在badger的事务上限以内时在单个事务中完成；超过上限时改为版本切换：
+// 新内容先写入包内部的新版本，再在一个小事务中原子地切换该前缀的生效版本，旧版本在后台删除。
+// 切换后该前缀的读取经版本指针解析，仅 Get/GetS/GetBytes/Exists/ExistsBytes 支持，
+// 之后也只能通过 ReplacePrefix 修改该前缀下的内容；前缀为空或覆盖包内部保留的key时不能切换，此时返回错误
+// 示例：
+//
+//	err := db.ReplacePrefix("config:", map[string][]byte{
+//	    "config:timeout": []byte("30"),
+//	    "config:retries": []byte("3"),
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ReplacePrefix(prefix string, kvs map[string][]byte) error {
+	for key := range kvs {
+		if !strings.HasPrefix(key, prefix) {
+			return fmt.Errorf("%w: %q", ErrKeyOutsidePrefix, key)
+		}
+	}
+	if err := b.cfg.checkValueLens(kvs); err != nil {
+		return err
+	}
+	defer b.purgeReadCache()
+
+	if _, ok := b.versionedPrefixOf([]byte(prefix)); ok {
+		return b.replacePrefixVersioned(prefix, kvs)
+	}
+
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		// 读取指针使本事务与并发的版本切换冲突，切换后改为版本切换
+		gen, err := readPrefixPointer(txn, prefix)
+		if err != nil {
+			return err
+		}
+		if gen != 0 {
+			return errPrefixVersioned
+		}
+
+		var stale [][]byte
+
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().Key()
+			if isReservedKey(key) {
+				continue
+			}
+			if _, ok := kvs[string(key)]; !ok {
+				stale = append(stale, it.Item().KeyCopy(nil))
+			}
+		}
+		it.Close()
+
+		for _, key := range stale {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		for key, value := range kvs {
+			if err := txn.Set([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == badger.ErrTxnTooBig || err == errPrefixVersioned {
+		return b.replacePrefixVersioned(prefix, kvs)
+	}
+	return err
+}
+
+// BulkSet 使用badger的 WriteBatch 批量写入多个key，适合导入大量数据
+// WriteBatch 会自动拆分为多个事务并在后台并发提交，吞吐量远高于逐个调用 Set；
+// 但整体不是原子的，返回错误时部分数据可能已经写入。需要原子写入时应使用 MSet
+// 示例：
+//
+//	err := db.BulkSet(records)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) BulkSet(pairs map[string][]byte) error {
+	w := b.NewBulkWriter()
+	defer w.Cancel()
+
+	for key, value := range pairs {
+		if err := w.Set(key, value); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// BulkWriter 流式批量写入器，基于badger的 WriteBatch
+// 数据积累到事务上限时自动提交，无需在内存中缓存全部数据。
+// 写入完成后必须调用 Flush 提交剩余数据，或调用 Cancel 放弃；二者调用后写入器不能再使用
+type BulkWriter struct {
+	db *BadgerDB
+	wb *badger.WriteBatch
+}
+
+// NewBulkWriter 创建一个流式批量写入器
+// 示例：
+//
+//	w := db.NewBulkWriter()
+//	defer w.Cancel()
+//	for scanner.Scan() {
+//	    key, value := parse(scanner.Bytes())
+//	    if err := w.Set(key, value); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//	if err := w.Flush(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) NewBulkWriter() *BulkWriter {
+	return &BulkWriter{db: b, wb: b.db.NewWriteBatch()}
+}
+
+// Set 写入一个key，value在调用返回后即可复用
+func (w *BulkWriter) Set(key string, value []byte) error {
+	if err := w.db.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	return w.wb.Set([]byte(key), append([]byte{}, value...))
+}
+
+// Flush 提交所有尚未提交的数据并等待完成
+func (w *BulkWriter) Flush() error {
+	defer w.db.purgeReadCache()
+	return w.wb.Flush()
+}
+
+// Cancel 放弃尚未提交的数据，已自动提交的部分不会回滚；在 Flush 之后调用不会产生影响
+func (w *BulkWriter) Cancel() {
+	w.wb.Cancel()
+}
@@ -0,0 +1,339 @@
+package rbadger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestGetManyCtx 测试GetManyCtx方法
+func TestGetManyCtx(t *testing.T) {
+	db := newTestDB(t)
+
+	var keys []string
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("item:%d", i)
+		if err := db.SetS(key, fmt.Sprintf("value%d", i)); err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	keys = append(keys, "item:missing")
+
+	values, err := db.GetManyCtx(context.Background(), keys, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 100 {
+		t.Errorf("期望获取100个值，实际获取%d个", len(values))
+	}
+	if string(values["item:42"]) != "value42" {
+		t.Errorf("item:42的值不正确: %s", values["item:42"])
+	}
+
+	// 已取消的ctx应返回错误
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.GetManyCtx(ctx, keys, 4); err != context.Canceled {
+		t.Errorf("期望返回context.Canceled，实际为%v", err)
+	}
+}
+
+// TestXMGetPartition 测试XMGetPartition方法
+func TestXMGetPartition(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetS("k1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExS("k2", "v2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExSecS("k3", "v3", 1); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+
+	hits, misses, err := db.XMGetPartition([]string{"k1", "k2", "k3", "k4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || string(hits["k1"]) != "v1" || string(hits["k2"]) != "v2" {
+		t.Errorf("命中结果不正确: %v", hits)
+	}
+	if len(misses) != 2 || misses[0] != "k3" || misses[1] != "k4" {
+		t.Errorf("未命中结果不正确: %v", misses)
+	}
+	if !waitDeleted(db, "k3") {
+		t.Error("过期的key应该已被删除")
+	}
+}
+
+// TestXMSetKeepTTL 测试XMSetKeepTTL方法
+func TestXMSetKeepTTL(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExS("k1", "old1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetS("k2", "old2"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.XMSetKeepTTL(map[string][]byte{
+		"k1": []byte("new1"),
+		"k2": []byte("new2"),
+		"k3": []byte("new3"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{"k1": "new1", "k2": "new2", "k3": "new3"} {
+		if val, _ := db.XGetS(key); val != want {
+			t.Errorf("%s的值期望为%s，实际为%s", key, want, val)
+		}
+	}
+	if ttl, _ := db.XTTL("k1"); ttl <= 3500 {
+		t.Errorf("k1应保留原有的过期时间，实际TTL: %d", ttl)
+	}
+	if ttl, _ := db.XTTL("k3"); ttl != -1 {
+		t.Errorf("新key应永不过期，实际TTL: %d", ttl)
+	}
+}
+
+// TestXMSetKeepTTLSliding 测试XMSetKeepTTL保留滑动过期设置
+func TestXMSetKeepTTLSliding(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetSliding("session", []byte("old"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	_, created, _, err := db.XGetMeta("session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetKeepTTL("session", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if _, c, _, _ := db.XGetMeta("session"); !c.Equal(created) {
+		t.Errorf("应保留原有的写入时间: %v, %v", c, created)
+	}
+
+	// 缩短剩余时间后读取，滑动过期的key应被顺延
+	if err := db.XExpire("session", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.XGetS("session"); v != "new" {
+		t.Errorf("值不正确: %q", v)
+	}
+	if ttl, _ := db.XTTL("session"); ttl <= 10 {
+		t.Errorf("XSetKeepTTL后应仍为滑动过期，实际TTL: %d", ttl)
+	}
+}
+
+// TestReplacePrefix 测试整体替换前缀下的内容
+func TestReplacePrefix(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, key := range []string{"cfg:a", "cfg:b", "other:c"} {
+		if err := db.SetS(key, "old"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := db.ReplacePrefix("cfg:", map[string][]byte{
+		"cfg:b": []byte("new"),
+		"cfg:d": []byte("new"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := db.FindKeys("cfg:")
+	if len(keys) != 2 {
+		t.Errorf("替换后的key不正确: %v", keys)
+	}
+	if v, _ := db.GetS("cfg:b"); v != "new" {
+		t.Errorf("cfg:b应被更新: %q", v)
+	}
+	if db.Exists("cfg:a") {
+		t.Error("cfg:a应被删除")
+	}
+	if v, _ := db.GetS("other:c"); v != "old" {
+		t.Error("其他前缀不应受影响")
+	}
+
+	if err := db.ReplacePrefix("cfg:", map[string][]byte{"x": nil}); !errors.Is(err, ErrKeyOutsidePrefix) {
+		t.Errorf("应返回ErrKeyOutsidePrefix: %v", err)
+	}
+}
+
+// TestReplacePrefixVersioned 测试超过事务上限时通过版本切换整体替换前缀下的内容
+func TestReplacePrefixVersioned(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil).
+		WithMemTableSize(1 << 20).WithValueThreshold(1 << 10)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.SetS("cfg:old", "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	big := make(map[string][]byte)
+	for i := int64(0); i <= db.db.MaxBatchCount(); i++ {
+		big[fmt.Sprintf("cfg:%06d", i)] = []byte("v1")
+	}
+	if err := db.ReplacePrefix("cfg:", big); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.GetS("cfg:000001"); v != "v1" {
+		t.Errorf("切换后应读取到新版本的值: %q", v)
+	}
+	if db.Exists("cfg:old") {
+		t.Error("切换后不应读取到旧的key")
+	}
+
+	// 已切换的前缀即使数据量较小也通过版本切换替换
+	if err := db.ReplacePrefix("cfg:", map[string][]byte{"cfg:a": []byte("v2")}); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.GetS("cfg:a"); v != "v2" {
+		t.Errorf("cfg:a应为新版本的值: %q", v)
+	}
+	if _, err := db.Get("cfg:000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不在新版本中的key应不存在: %v", err)
+	}
+
+	// 重新加载版本化前缀列表后仍能经指针解析
+	db.versioned.Store(nil)
+	if err := db.loadVersionedPrefixes(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.GetS("cfg:a"); v != "v2" {
+		t.Errorf("重新加载后cfg:a的值不正确: %q", v)
+	}
+
+	// 旧版本和原有的普通key在后台被删除
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		old, _ := db.FindKeys("cfg:")
+		var stored int
+		db.db.View(func(txn *badger.Txn) error {
+			it := txn.NewIterator(badger.IteratorOptions{Prefix: []byte(prefixDataPrefix)})
+			defer it.Close()
+			for it.Rewind(); it.Valid(); it.Next() {
+				stored++
+			}
+			return nil
+		})
+		if len(old) == 0 && stored == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("旧数据未被删除: 普通key %d个, 版本数据 %d个", len(old), stored)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestMGetMSet 测试MGet和MSet方法
+func TestMGetMSet(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.MSetS(map[string]string{"k1": "v1", "k2": "v2"}); err != nil {
+		t.Fatal(err)
+	}
+	values, err := db.MGetS([]string{"k1", "k2", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values["k1"] != "v1" || values["k2"] != "v2" {
+		t.Errorf("结果不正确: %v", values)
+	}
+	if _, ok := values["missing"]; ok {
+		t.Error("不存在的key不应出现在结果中")
+	}
+}
+
+// TestBulkSet 测试BulkSet与流式BulkWriter
+func TestBulkSet(t *testing.T) {
+	db := newTestDB(t)
+
+	pairs := make(map[string][]byte)
+	for i := 0; i < 5000; i++ {
+		pairs[fmt.Sprintf("bulk:%05d", i)] = []byte(fmt.Sprint(i))
+	}
+	if err := db.BulkSet(pairs); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := db.CountKeys("bulk:"); n != 5000 {
+		t.Errorf("BulkSet写入数量不正确: %d", n)
+	}
+
+	w := db.NewBulkWriter()
+	buf := []byte("x")
+	for i := 0; i < 100; i++ {
+		buf[0] = byte('a' + i%26)
+		if err := w.Set(fmt.Sprintf("stream:%03d", i), buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Cancel()
+	if v, _ := db.GetS("stream:001"); v != "b" {
+		t.Errorf("写入后复用value不应影响已写入的数据: %s", v)
+	}
+
+	// 取消的写入器不提交剩余数据
+	w = db.NewBulkWriter()
+	w.Set("canceled", []byte("v"))
+	w.Cancel()
+	if db.Exists("canceled") {
+		t.Error("取消后不应写入数据")
+	}
+}
+
+// benchmarkPairs 生成基准测试使用的数据
+func benchmarkPairs(n int) map[string][]byte {
+	pairs := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		pairs[fmt.Sprintf("key:%07d", i)] = []byte("value")
+	}
+	return pairs
+}
+
+// BenchmarkSetLoop 逐个调用Set写入作为对照
+func BenchmarkSetLoop(b *testing.B) {
+	pairs := benchmarkPairs(10000)
+	for i := 0; i < b.N; i++ {
+		db := newBenchDB(b)
+		for key, value := range pairs {
+			if err := db.Set(key, value); err != nil {
+				b.Fatal(err)
+			}
+		}
+		db.Close()
+	}
+}
+
+// BenchmarkBulkSet 使用BulkSet批量写入
+func BenchmarkBulkSet(b *testing.B) {
+	pairs := benchmarkPairs(10000)
+	for i := 0; i < b.N; i++ {
+		db := newBenchDB(b)
+		if err := db.BulkSet(pairs); err != nil {
+			b.Fatal(err)
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,68 @@
+package rbadger
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// Codec 定义 X 系列方法存储 CacheType 时使用的编解码器
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// GobCodec 使用 gob 编解码，是默认的编解码器
+type GobCodec struct{}
+
+// Marshal 使用 gob 编码v
+func (GobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 使用 gob 将data解码到v中
+func (GobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// JSONCodec 使用 JSON 编解码，存储的数据可以被其他语言读取
+// 存储格式为3字节的版本头部（0x00 0xcb 版本号）后接JSON
+type JSONCodec struct{}
+
+// Marshal 使用 JSON 编码v
+func (JSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 使用 JSON 将data解码到v中
+func (JSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec 设置 X 系列方法存储 CacheType 时使用的编解码器，默认为 GobCodec
+// 同一个数据库必须始终使用相同的编解码器，否则已有的数据将无法解码
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithCodec(JSONCodec{}))
+func WithCodec(codec Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// NewBadgerDBWithCodec 创建一个使用指定编解码器存储 CacheType 的 BadgerDB 实例
+// 等同于 NewBadgerDB(path, WithCodec(codec))
+// 示例：
+//
+//	db, err := NewBadgerDBWithCodec("./data", JSONCodec{})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer db.Close()
+func NewBadgerDBWithCodec(path string, codec Codec, options ...Option) (*BadgerDB, error) {
+	return NewBadgerDB(path, append(options, WithCodec(codec))...)
+}
@@ -0,0 +1,78 @@
+package rbadger
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestJSONCodec 测试使用JSON编解码器存储缓存数据
+func TestJSONCodec(t *testing.T) {
+	db, err := NewBadgerDBWithCodec(t.TempDir(), JSONCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.XSetExS("k", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.XGetS("k"); err != nil || v != "v" {
+		t.Errorf("读取结果不正确: %q, %v", v, err)
+	}
+	if ttl, _ := db.XTTL("k"); ttl <= 0 {
+		t.Errorf("剩余时间不正确: %d", ttl)
+	}
+	if n, err := db.XIncr("n"); err != nil || n != 1 {
+		t.Errorf("递增结果不正确: %d, %v", n, err)
+	}
+
+	// 存储的数据为版本头部加JSON格式
+	raw, err := db.Get("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(raw, cacheMagic) || raw[len(cacheMagic)] != cacheVersion {
+		t.Fatalf("存储的数据应带有版本头部: %q", raw)
+	}
+	var cache CacheType
+	if err := json.Unmarshal(raw[len(cacheMagic)+1:], &cache); err != nil || string(cache.Data) != "v" {
+		t.Errorf("存储的数据应为JSON: %s, %v", raw, err)
+	}
+}
+
+// TestDecodeLegacyCache 测试读取不带版本头部的旧数据
+func TestDecodeLegacyCache(t *testing.T) {
+	db := newTestDB(t)
+
+	var buf bytes.Buffer
+	legacy := struct {
+		Data   []byte
+		Expire int64
+	}{Data: []byte("old"), Expire: time.Now().Add(time.Hour).Unix()}
+	if err := gob.NewEncoder(&buf).Encode(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set("legacy", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	value, created, expire, err := db.XGetMeta("legacy")
+	if err != nil || string(value) != "old" {
+		t.Fatalf("旧数据应能正常读取: %q, %v", value, err)
+	}
+	if !created.IsZero() || expire.IsZero() {
+		t.Errorf("旧数据的写入时间应为零值，过期时间应保留: %v, %v", created, expire)
+	}
+
+	// 不支持的版本返回错误
+	if err := db.Set("future", append(append([]byte{}, cacheMagic...), cacheVersion+1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.XGet("future"); !errors.Is(err, ErrCorruptCacheEntry) {
+		t.Errorf("不支持的版本应返回ErrCorruptCacheEntry: %v", err)
+	}
+}
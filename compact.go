@@ -0,0 +1,22 @@
+package rbadger
+
+// compactWorker 删除触发的压缩任务名称
+const compactWorker = "compact"
+
+// noteDeletes 记录删除的key数量，达到 WithDeleteCompactionThreshold 设置的阈值时在后台触发一次压缩
+// 压缩正在进行时不会重复触发，计数在触发时清零
+func (b *BadgerDB) noteDeletes(n int) {
+	threshold := b.cfg.deleteCompactionThreshold
+	if threshold <= 0 || n <= 0 {
+		return
+	}
+	if b.deletes.Add(int64(n)) < int64(threshold) {
+		return
+	}
+	b.deletes.Store(0)
+
+	// 压缩无法中途停止，Close 时会等待其完成
+	b.startWorker(compactWorker, func(stop <-chan struct{}) {
+		b.db.Flatten(1)
+	})
+}
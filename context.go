@@ -0,0 +1,45 @@
+package rbadger
+
+import (
+	"context"
+	"fmt"
+)
+
+// scanCheckInterval Ctx系列扫描方法每遍历多少个key检查一次ctx是否结束
+const scanCheckInterval = 1000
+
+// checkCtx 检查ctx是否已结束，结束时返回包装了ctx错误的error
+// 调用方可以通过 errors.Is(err, context.Canceled) 或 errors.Is(err, context.DeadlineExceeded) 区分取消与超时
+func checkCtx(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("rbadger: %s aborted: %w", op, err)
+	}
+	return nil
+}
+
+// GetCtx 与 Get 相同，但ctx已结束时不执行读取，直接返回包装后的ctx错误
+// 示例：
+//
+//	value, err := db.GetCtx(r.Context(), "user:1")
+//	if errors.Is(err, context.Canceled) {
+//	    return
+//	}
+func (b *BadgerDB) GetCtx(ctx context.Context, key string) ([]byte, error) {
+	if err := checkCtx(ctx, "get"); err != nil {
+		return nil, err
+	}
+	return b.Get(key)
+}
+
+// SetCtx 与 Set 相同，但ctx已结束时不执行写入，直接返回包装后的ctx错误
+// 示例：
+//
+//	if err := db.SetCtx(r.Context(), "user:1", data); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetCtx(ctx context.Context, key string, value []byte) error {
+	if err := checkCtx(ctx, "set"); err != nil {
+		return err
+	}
+	return b.Set(key, value)
+}
@@ -0,0 +1,59 @@
+package rbadger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestCtxVariants 测试ctx结束时各方法返回可区分的包装错误
+func TestCtxVariants(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 3000; i++ {
+		db.SetS(fmt.Sprintf("k:%04d", i), "v")
+	}
+
+	if err := db.SetCtx(context.Background(), "a", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.GetCtx(context.Background(), "a"); err != nil || string(v) != "1" {
+		t.Errorf("GetCtx结果不正确: %s, %v", v, err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.GetCtx(canceled, "a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx取消时GetCtx应返回context.Canceled: %v", err)
+	}
+	if err := db.SetCtx(canceled, "b", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx取消时SetCtx应返回context.Canceled: %v", err)
+	}
+	if db.Exists("b") {
+		t.Error("ctx取消时不应写入数据")
+	}
+
+	expired, cancel2 := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel2()
+	if _, _, err := db.ScanKeysCtx(expired, "k:", "", 10); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("超时时ScanKeysCtx应返回context.DeadlineExceeded: %v", err)
+	}
+
+	// 扫描过程中取消，应在下一次检查时停止
+	ctx, cancel3 := context.WithCancel(context.Background())
+	n := 0
+	err := db.ForEachCtx(ctx, "k:", func(key string, value []byte) error {
+		if n++; n == 10 {
+			cancel3()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("扫描中取消时ForEachCtx应返回context.Canceled: %v", err)
+	}
+	if n > scanCheckInterval+1 {
+		t.Errorf("取消后应及时停止扫描，实际遍历了%d个key", n)
+	}
+}
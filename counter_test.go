@@ -0,0 +1,65 @@
+package rbadger
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestCounter 测试合并操作符计数器的并发增加与持久化
+func TestCounter(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db")
+	db, err := NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := db.NewCounter("counter:a")
+	if db.NewCounter("counter:a") != a {
+		t.Error("相同key应返回同一个计数器")
+	}
+	if n, err := a.Get(); err != nil || n != 0 {
+		t.Errorf("未增加过的计数器应为0: %d, %v", n, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := a.Add(2); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := db.NewCounter("counter:b").Add(-1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, _ := a.Get(); n != 100 {
+		t.Errorf("计数器a的值不正确: %d", n)
+	}
+	if n, _ := db.NewCounter("counter:b").Get(); n != -50 {
+		t.Errorf("计数器b的值不正确: %d", n)
+	}
+
+	// Close 会合并剩余的增量，重新打开后值保持不变，且可由GetInt读取
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, _ := db.NewCounter("counter:a").Get(); n != 100 {
+		t.Errorf("重新打开后计数器的值不正确: %d", n)
+	}
+	if n, _ := db.GetInt("counter:a"); n != 100 {
+		t.Errorf("合并后应可由GetInt读取: %d", n)
+	}
+}
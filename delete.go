@@ -0,0 +1,358 @@
+package rbadger
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// deleteCheckInterval 扫描时每遍历多少个key检查一次ctx是否被取消
+const deleteCheckInterval = 1000
+
+// MDel 删除多个key，返回实际被删除（删除前存在）的key数量
+// 不超过 expireBatchSize 个key时在一个事务中完成；key较多时按批拆分为多个事务，
+// 每个key都会被处理，遇到并发冲突时自动重试。返回错误时已提交的批次不会回滚
+// 示例：
+//
+//	n, err := db.MDel("session:1", "session:2", "session:3")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("删除了%d个key\n", n)
+func (b *BadgerDB) MDel(keys ...string) (int, error) {
+	removed := 0
+	for start := 0; start < len(keys); start += expireBatchSize {
+		end := start + expireBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var n int
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			n = 0
+			for _, key := range keys[start:end] {
+				_, err := txn.Get([]byte(key))
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if err := txn.Delete([]byte(key)); err != nil {
+					return err
+				}
+				n++
+			}
+			return nil
+		})
+		b.invalidate(keys[start:end]...)
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+		b.noteDeletes(n)
+	}
+	return removed, nil
+}
+
+// DelPrefix 使用 badger 的 DropPrefix 删除指定前缀下的所有key
+// 直接丢弃整个前缀范围的数据，比逐个删除快得多，适合清空数百万个key的命名空间。
+// 该操作不是事务性的：执行期间对该前缀的并发写入可能被删除也可能被保留；方法在删除完成后才返回。
+// 由 SetExIndexed 为这些key记录的过期时间索引会一并删除。
+// 前缀为空或覆盖包内部保留的key时返回错误，此时应使用 DeleteWhere
+// 示例：
+//
+//	if err := db.DelPrefix("session:"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DelPrefix(prefix string) error {
+	if strings.HasPrefix(reservedPrefix, prefix) {
+		return errors.New("rbadger: prefix covers reserved keys")
+	}
+	defer b.purgeReadCache()
+	return b.db.DropPrefix([]byte(prefix), ttlIndexKey(prefix))
+}
+
+// DropAll 使用 badger 的 DropAll 清空整个数据库，包括包内部保留的key
+// 比逐个删除快得多，适合测试和管理工具。执行期间会阻塞其他所有读写操作，完成后数据库仍可正常使用。
+// 有备份（Backup）正在进行时不会执行，并返回 ErrBackupInProgress
+// 示例：
+//
+//	if err := db.DropAll(); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DropAll() error {
+	if !b.backupMu.TryLock() {
+		return ErrBackupInProgress
+	}
+	defer b.backupMu.Unlock()
+	defer b.purgeReadCache()
+	return b.db.DropAll()
+}
+
+// DeleteWhere 删除指定前缀下所有满足条件的key，返回删除的数量
+// 先在只读事务中扫描出满足条件的key，再在扫描结束后分批删除，避免与迭代产生事务冲突。
+// 删除时会确认key在扫描后未被改写，期间被重新写入或已被删除的key会被跳过且不计入返回数量
+// 示例：
+//
+//	n, err := db.DeleteWhere("session:", func(key string, value []byte) bool {
+//	    return bytes.Contains(value, []byte("tenant=42"))
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DeleteWhere(prefix string, pred func(key string, value []byte) bool) (int, error) {
+	return b.deleteWhere(context.Background(), prefix, pred)
+}
+
+// DeleteAllWhere 扫描整个数据库并删除所有满足条件的key，返回删除的数量
+// 适用于清理分散在多个前缀下的数据，例如删除某个租户的全部记录
+// 示例：
+//
+//	n, err := db.DeleteAllWhere(func(key string, value []byte) bool {
+//	    return strings.Contains(key, ":tenant42:")
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("删除了%d个key\n", n)
+func (b *BadgerDB) DeleteAllWhere(pred func(key string, value []byte) bool) (int, error) {
+	return b.deleteWhere(context.Background(), "", pred)
+}
+
+// DeleteAllWhereCtx 与 DeleteAllWhere 相同，但可以通过ctx取消
+// 取消时返回已删除的数量及ctx的错误
+func (b *BadgerDB) DeleteAllWhereCtx(ctx context.Context, pred func(key string, value []byte) bool) (int, error) {
+	return b.deleteWhere(ctx, "", pred)
+}
+
+// scannedKey 扫描时记录的key及其版本，删除前用于确认key未被改写
+type scannedKey struct {
+	key     []byte
+	version uint64
+}
+
+// deleteWhere 扫描指定前缀并删除所有满足条件的key
+func (b *BadgerDB) deleteWhere(ctx context.Context, prefix string, pred func(key string, value []byte) bool) (int, error) {
+	var matched []scannedKey
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		n := 0
+		for it.Rewind(); it.Valid(); it.Next() {
+			if n%deleteCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			n++
+
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				if pred(string(item.Key()), val) {
+					matched = append(matched, scannedKey{key: item.KeyCopy(nil), version: item.Version()})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return b.deleteKeys(ctx, matched)
+}
+
+// deleteKeys 分批删除给定的key，每批之间检查ctx是否被取消
+// 删除前在同一事务中确认key仍存在且版本与扫描时一致，扫描后被改写或已删除的key会被跳过。
+// 返回实际删除的数量
+func (b *BadgerDB) deleteKeys(ctx context.Context, keys []scannedKey) (int, error) {
+	deleted := 0
+	for start := 0; start < len(keys); start += expireBatchSize {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		end := start + expireBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		var removed int
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			removed = 0
+			for _, k := range keys[start:end] {
+				item, err := txn.Get(k.key)
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if item.Version() != k.version {
+					continue
+				}
+				if err := txn.Delete(k.key); err != nil {
+					return err
+				}
+				removed++
+			}
+			return nil
+		})
+		for _, k := range keys[start:end] {
+			b.invalidate(string(k.key))
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted += removed
+		b.noteDeletes(removed)
+	}
+	return deleted, nil
+}
+
+// Drain 依次读取指定前缀下的key并交给fn处理，fn返回nil后删除该key
+// fn 返回错误时该key被保留以便重试，同时停止处理并返回该错误。
+// 处理期间被重新写入的key不会被删除，其新值会在下一次 Drain 时再交给fn处理。
+// 返回成功处理并删除的key数量，适合以"至少一次"语义将积压数据转移到其他系统
+// 示例：
+//
+//	n, err := db.Drain("outbox:", func(key string, value []byte) error {
+//	    return publish(value)
+//	})
+//	if err != nil {
+//	    log.Printf("转移中断: %v，已转移%d条", err, n)
+//	}
+func (b *BadgerDB) Drain(prefix string, fn func(key string, value []byte) error) (int, error) {
+	type kv struct {
+		scannedKey
+		value []byte
+	}
+
+	drained := 0
+	var seek []byte
+	for {
+		var batch []kv
+		err := b.db.View(func(txn *badger.Txn) error {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte(prefix)
+			it := txn.NewIterator(opts)
+			defer it.Close()
+
+			if seek == nil {
+				it.Rewind()
+			} else {
+				it.Seek(seek)
+			}
+			for ; it.Valid() && len(batch) < expireBatchSize; it.Next() {
+				item := it.Item()
+				if isReservedKey(item.Key()) {
+					continue
+				}
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				batch = append(batch, kv{
+					scannedKey: scannedKey{key: item.KeyCopy(nil), version: item.Version()},
+					value:      value,
+				})
+			}
+			return nil
+		})
+		if err != nil || len(batch) == 0 {
+			return drained, err
+		}
+
+		var done []scannedKey
+		var fnErr error
+		for _, p := range batch {
+			if fnErr = fn(string(p.key), p.value); fnErr != nil {
+				break
+			}
+			done = append(done, p.scannedKey)
+		}
+
+		n, err := b.deleteKeys(context.Background(), done)
+		drained += n
+		if err != nil {
+			return drained, err
+		}
+		if fnErr != nil {
+			return drained, fnErr
+		}
+
+		// 从本批最后一个key之后继续
+		seek = append(batch[len(batch)-1].key, 0)
+	}
+}
+
+// ClaimPrefix 在一个事务中读取并删除指定前缀下最多limit个key，返回被领取的key及其值
+// 并发的多个领取者不会领取到相同的key：读取的key被其他事务删除时本事务会发生冲突并自动重试。
+// 适合作为轻量的任务分发方式，limit<=0 时不做任何操作
+// 示例：
+//
+//	tasks, err := db.ClaimPrefix("task:", 10)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for key, payload := range tasks {
+//	    process(key, payload)
+//	}
+func (b *BadgerDB) ClaimPrefix(prefix string, limit int) (map[string][]byte, error) {
+	if limit <= 0 {
+		return map[string][]byte{}, nil
+	}
+
+	var claimed map[string][]byte
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		claimed = make(map[string][]byte, limit)
+
+		var keys [][]byte
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		for it.Rewind(); it.Valid() && len(keys) < limit; it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				it.Close()
+				return err
+			}
+			key := item.KeyCopy(nil)
+			keys = append(keys, key)
+			claimed[string(key)] = value
+		}
+		it.Close()
+
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range claimed {
+		b.invalidate(key)
+	}
+	b.noteDeletes(len(claimed))
+	return claimed, nil
+}
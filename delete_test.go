@@ -0,0 +1,327 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestDeleteAllWhere 测试DeleteAllWhere方法
+func TestDeleteAllWhere(t *testing.T) {
+	db := newTestDB(t)
+
+	data := map[string]string{
+		"user:t1:1":  "a",
+		"user:t2:1":  "b",
+		"order:t1:1": "c",
+		"order:t2:1": "d",
+	}
+	for key, value := range data {
+		if err := db.SetS(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SetSchemaVersion(1); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := db.DeleteAllWhere(func(key string, value []byte) bool {
+		return strings.Contains(key, ":t1:")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("期望删除2个key，实际删除%d个", n)
+	}
+
+	keys, _ := db.FindKeys("")
+	if len(keys) != 2 {
+		t.Errorf("期望剩余2个key，实际剩余: %v", keys)
+	}
+
+	// 保留的元数据不受影响
+	n, err = db.DeleteAllWhere(func(key string, value []byte) bool { return true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("期望删除2个key，实际删除%d个", n)
+	}
+	if v, _ := db.SchemaVersion(); v != 1 {
+		t.Error("元数据不应被删除")
+	}
+}
+
+// TestDeleteWhereRewritten 测试扫描后被改写或删除的key不会被删除，也不会被计数
+func TestDeleteWhereRewritten(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, key := range []string{"job:1", "job:2", "job:3"} {
+		if err := db.SetS(key, "old"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := db.DeleteWhere("job:", func(key string, value []byte) bool {
+		// 模拟扫描与删除之间的并发写入
+		switch key {
+		case "job:1":
+			if err := db.SetS(key, "new"); err != nil {
+				t.Fatal(err)
+			}
+		case "job:2":
+			if err := db.Del(key); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return string(value) == "old"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("期望只删除1个key，实际删除%d个", n)
+	}
+	if v, _ := db.GetS("job:1"); v != "new" {
+		t.Errorf("扫描后被改写的key不应被删除, 实际值: %q", v)
+	}
+	if db.Exists("job:3") {
+		t.Error("满足条件且未被改写的key应被删除")
+	}
+}
+
+// TestDrain 测试Drain方法
+func TestDrain(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, key := range []string{"outbox:1", "outbox:2", "outbox:3", "other:1"} {
+		if err := db.SetS(key, "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failed := errors.New("publish failed")
+	var seen []string
+	n, err := db.Drain("outbox:", func(key string, value []byte) error {
+		if key == "outbox:3" {
+			return failed
+		}
+		seen = append(seen, key)
+		return nil
+	})
+	if err != failed {
+		t.Errorf("期望返回fn的错误，实际为%v", err)
+	}
+	if n != 2 || len(seen) != 2 {
+		t.Errorf("期望转移2条，实际为%d", n)
+	}
+
+	keys, _ := db.FindKeys("outbox:")
+	if len(keys) != 1 || keys[0] != "outbox:3" {
+		t.Errorf("处理失败的key应被保留: %v", keys)
+	}
+
+	n, err = db.Drain("outbox:", func(key string, value []byte) error { return nil })
+	if err != nil || n != 1 {
+		t.Errorf("重试时期望转移1条，实际为%d, err=%v", n, err)
+	}
+	if !db.Exists("other:1") {
+		t.Error("其他前缀的key不应被删除")
+	}
+}
+
+// TestDrainRewritten 测试处理期间被重新写入的key不会被删除
+func TestDrainRewritten(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetS("outbox:1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := db.Drain("outbox:", func(key string, value []byte) error {
+		// 模拟处理期间的并发写入
+		return db.SetS(key, "v2")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("被重新写入的key不应计入转移数量, 实际为%d", n)
+	}
+
+	var got []string
+	n, err = db.Drain("outbox:", func(key string, value []byte) error {
+		got = append(got, string(value))
+		return nil
+	})
+	if err != nil || n != 1 {
+		t.Errorf("期望转移1条，实际为%d, err=%v", n, err)
+	}
+	if len(got) != 1 || got[0] != "v2" {
+		t.Errorf("新写入的值应被交给fn处理: %v", got)
+	}
+}
+
+// TestClaimPrefix 测试并发领取时结果不重叠
+func TestClaimPrefix(t *testing.T) {
+	db := newTestDB(t)
+
+	const total = 200
+	for i := 0; i < total; i++ {
+		if err := db.SetS(fmt.Sprintf("task:%03d", i), "payload"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		seen    = make(map[string]int)
+		wg      sync.WaitGroup
+		claimer = func() {
+			defer wg.Done()
+			for {
+				tasks, err := db.ClaimPrefix("task:", 7)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(tasks) == 0 {
+					return
+				}
+				mu.Lock()
+				for key := range tasks {
+					seen[key]++
+				}
+				mu.Unlock()
+			}
+		}
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go claimer()
+	}
+	wg.Wait()
+
+	if len(seen) != total {
+		t.Errorf("期望领取%d个key，实际为%d个", total, len(seen))
+	}
+	for key, n := range seen {
+		if n != 1 {
+			t.Errorf("key %s 被领取了%d次", key, n)
+		}
+	}
+	if keys, _ := db.FindKeys("task:"); len(keys) != 0 {
+		t.Errorf("领取后不应剩余key: %v", keys)
+	}
+}
+
+// TestMDel 测试MDel方法
+func TestMDel(t *testing.T) {
+	db := newTestDB(t)
+
+	keys := make([]string, 0, 2500)
+	for i := 0; i < 2500; i++ {
+		key := fmt.Sprintf("session:%d", i)
+		keys = append(keys, key)
+		if i%2 == 0 {
+			db.SetS(key, "v")
+		}
+	}
+
+	n, err := db.MDel(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1250 {
+		t.Errorf("期望删除1250个key，实际为%d个", n)
+	}
+	if left, _ := db.FindKeys("session:"); len(left) != 0 {
+		t.Errorf("不应剩余key: %d个", len(left))
+	}
+}
+
+// TestDelPrefix 测试DelPrefix方法
+func TestDelPrefix(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 10; i++ {
+		db.SetS(fmt.Sprintf("session:%d", i), "v")
+	}
+	db.SetS("user:1", "v")
+	db.SetMeta("m", []byte("v"))
+	db.SetExIndexed("session:indexed", []byte("v"), time.Hour)
+	db.SetExIndexed("user:indexed", []byte("v"), time.Hour)
+
+	if err := db.DelPrefix("session:"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := db.FindKeys("session:"); len(keys) != 0 {
+		t.Errorf("前缀下不应剩余key: %v", keys)
+	}
+	if !db.Exists("user:1") {
+		t.Error("其他前缀不应受影响")
+	}
+	db.db.View(func(txn *badger.Txn) error {
+		if expire, _ := readTTLIndex(txn, "session:indexed"); expire != 0 {
+			t.Error("前缀下key的过期时间索引应被删除")
+		}
+		if expire, _ := readTTLIndex(txn, "user:indexed"); expire == 0 {
+			t.Error("其他前缀的过期时间索引不应受影响")
+		}
+		return nil
+	})
+
+	if err := db.DelPrefix(""); err == nil {
+		t.Error("空前缀应返回错误")
+	}
+	if v, _ := db.GetMeta("m"); string(v) != "v" {
+		t.Error("元数据不应被删除")
+	}
+}
+
+// TestDropAll 测试清空数据库，以及备份进行中时拒绝执行
+func TestDropAll(t *testing.T) {
+	db := newTestDB(t)
+	for i := 0; i < 100; i++ {
+		db.SetS(fmt.Sprintf("k:%d", i), "v")
+	}
+
+	// 读取到第一个字节后备份仍在进行，此时DropAll应被拒绝
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.Backup(pw, 0)
+		pw.CloseWithError(err)
+		done <- err
+	}()
+	if _, err := pr.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DropAll(); !errors.Is(err, ErrBackupInProgress) {
+		t.Errorf("备份进行中应返回ErrBackupInProgress: %v", err)
+	}
+	io.Copy(io.Discard, pr)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.DropAll(); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := db.CountKeys(""); n != 0 {
+		t.Errorf("DropAll后不应有key，实际为%d个", n)
+	}
+
+	// 清空后数据库仍可正常使用
+	db.SetS("after", "1")
+	if v, _ := db.GetS("after"); v != "1" {
+		t.Errorf("DropAll后写入的数据读取不正确: %s", v)
+	}
+}
@@ -0,0 +1,117 @@
+package rbadger
+
+import (
+	"crypto/sha256"
+	"sort"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// DiffPrefix 以当前数据库为基准，比较指定前缀下与other数据库的差异
+// added 为仅存在于other中的key，removed 为仅存在于当前数据库中的key，
+// changed 为两边都存在但值不同的key，结果均按字典序排列。
+// 只需比较key时可使用更快的 DiffPrefixKeys
+// 示例：
+//
+//	added, removed, changed, err := prod.DiffPrefix(staging, "config:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DiffPrefix(other *BadgerDB, prefix string) (added, removed, changed []string, err error) {
+	digests := make(map[string][sha256.Size]byte)
+	err = b.scanPrefix(prefix, true, func(key string, value []byte) {
+		digests[key] = sha256.Sum256(value)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = other.scanPrefix(prefix, true, func(key string, value []byte) {
+		digest, ok := digests[key]
+		if !ok {
+			added = append(added, key)
+			return
+		}
+		if digest != sha256.Sum256(value) {
+			changed = append(changed, key)
+		}
+		delete(digests, key)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for key := range digests {
+		removed = append(removed, key)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed, nil
+}
+
+// DiffPrefixKeys 以当前数据库为基准，仅比较指定前缀下与other数据库的key差异，不读取值
+// 示例：
+//
+//	added, removed, err := prod.DiffPrefixKeys(staging, "config:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DiffPrefixKeys(other *BadgerDB, prefix string) (added, removed []string, err error) {
+	keys := make(map[string]struct{})
+	err = b.scanPrefix(prefix, false, func(key string, _ []byte) {
+		keys[key] = struct{}{}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = other.scanPrefix(prefix, false, func(key string, _ []byte) {
+		if _, ok := keys[key]; !ok {
+			added = append(added, key)
+			return
+		}
+		delete(keys, key)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key := range keys {
+		removed = append(removed, key)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed, nil
+}
+
+// scanPrefix 遍历指定前缀下的所有非保留key，withValues 为false时不读取值
+// fn 中的value仅在回调期间有效
+func (b *BadgerDB) scanPrefix(prefix string, withValues bool, fn func(key string, value []byte)) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		opts.PrefetchValues = withValues
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			if !withValues {
+				fn(string(item.Key()), nil)
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				fn(string(item.Key()), val)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
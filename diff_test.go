@@ -0,0 +1,43 @@
+package rbadger
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDiffPrefix 测试DiffPrefix和DiffPrefixKeys方法
+func TestDiffPrefix(t *testing.T) {
+	a := newTestDB(t)
+	b := newTestDB(t)
+
+	a.SetS("cfg:same", "1")
+	a.SetS("cfg:changed", "old")
+	a.SetS("cfg:removed", "x")
+	a.SetS("other:ignored", "x")
+
+	b.SetS("cfg:same", "1")
+	b.SetS("cfg:changed", "new")
+	b.SetS("cfg:added", "y")
+
+	added, removed, changed, err := a.DiffPrefix(b, "cfg:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{"cfg:added"}) {
+		t.Errorf("added不正确: %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"cfg:removed"}) {
+		t.Errorf("removed不正确: %v", removed)
+	}
+	if !reflect.DeepEqual(changed, []string{"cfg:changed"}) {
+		t.Errorf("changed不正确: %v", changed)
+	}
+
+	added, removed, err = a.DiffPrefixKeys(b, "cfg:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(added, []string{"cfg:added"}) || !reflect.DeepEqual(removed, []string{"cfg:removed"}) {
+		t.Errorf("仅比较key的结果不正确: added=%v, removed=%v", added, removed)
+	}
+}
@@ -0,0 +1,176 @@
+package rbadger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// gzipMagic gzip 数据的魔数头
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SetAny 存储任意类型的值
+// []byte 和 string 会按原样存储，其他类型使用 JSON 编码后存储。
+// 由于依赖反射和 JSON 编码，性能不如类型明确的方法，适合原型开发和工具脚本
+// 示例：
+//
+//	err := db.SetAny("config", map[string]int{"port": 8080})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetAny(key string, v interface{}) error {
+	switch val := v.(type) {
+	case []byte:
+		return b.Set(key, val)
+	case string:
+		return b.SetS(key, val)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Set(key, data)
+}
+
+// GetAny 读取由 SetAny 存储的值并解码到dst中，dst必须是指针
+// dst 为 *[]byte 或 *string 时直接返回原始数据，其他类型使用 JSON 解码
+// 示例：
+//
+//	var config map[string]int
+//	if err := db.GetAny("config", &config); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetAny(key string, dst interface{}) error {
+	data, err := b.Get(key)
+	if err != nil {
+		return err
+	}
+
+	switch d := dst.(type) {
+	case *[]byte:
+		*d = data
+		return nil
+	case *string:
+		*d = string(data)
+		return nil
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// SetJSON 将v编码为JSON后存储
+// 与 SetAny 不同，[]byte 和 string 也会按JSON编码
+// 示例：
+//
+//	err := db.SetJSON("config:app", AppConfig{Port: 8080})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetJSON(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Set(key, data)
+}
+
+// GetJSON 读取由 SetJSON 存储的值并解码到dest中，dest必须是指针
+// key不存在时返回 ErrNotFound；数据无法解码时返回包含key的解码错误
+// 示例：
+//
+//	var cfg AppConfig
+//	err := db.GetJSON("config:app", &cfg)
+//	if errors.Is(err, rbadger.ErrNotFound) {
+//	    cfg = defaultConfig
+//	} else if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetJSON(key string, dest any) error {
+	data, err := b.Get(key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("rbadger: decode json for key %q: %w", key, err)
+	}
+	return nil
+}
+
+// GetMaybeCompressed 获取指定key的值，若值以gzip魔数头开头则自动解压后返回，否则原样返回
+// 适用于压缩与未压缩数据混合存储的场景，例如从旧存储格式迁移期间
+// 示例：
+//
+//	value, err := db.GetMaybeCompressed("doc:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetMaybeCompressed(key string) ([]byte, error) {
+	data, err := b.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// SetJSONIf 读取key当前以JSON存储的对象并交给cond判断，cond返回true时将newV以JSON编码写入
+// 读取、判断与写入在同一个事务中完成，遇到并发冲突时自动重试（cond可能被多次调用）。
+// key不存在时cond的exists为false，old为零值。返回是否写入
+// 示例：
+//
+//	ok, err := rbadger.SetJSONIf(db, "user:1", incoming, func(old User, exists bool) bool {
+//	    return !exists || incoming.UpdatedAt.After(old.UpdatedAt)
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func SetJSONIf[T any](db *BadgerDB, key string, newV T, cond func(old T, exists bool) bool) (bool, error) {
+	data, err := json.Marshal(newV)
+	if err != nil {
+		return false, err
+	}
+	if err := db.cfg.checkValueLen(data); err != nil {
+		return false, err
+	}
+	defer db.invalidate(key)
+
+	var written bool
+	err = db.updateWithRetry(func(txn *badger.Txn) error {
+		written = false
+
+		var old T
+		exists := true
+		item, err := txn.Get([]byte(key))
+		if err == badger.ErrKeyNotFound {
+			exists = false
+		} else if err != nil {
+			return err
+		} else if err := item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &old)
+		}); err != nil {
+			return err
+		}
+
+		if !cond(old, exists) {
+			return nil
+		}
+		written = true
+		return txn.Set([]byte(key), data)
+	})
+	if err != nil {
+		return false, err
+	}
+	return written, nil
+}
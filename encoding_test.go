@@ -0,0 +1,134 @@
+package rbadger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+)
+
+// TestSetAnyGetAny 测试SetAny和GetAny方法
+func TestSetAnyGetAny(t *testing.T) {
+	db := newTestDB(t)
+
+	type config struct {
+		Host string
+		Port int
+	}
+
+	if err := db.SetAny("cfg", config{Host: "localhost", Port: 8080}); err != nil {
+		t.Fatal(err)
+	}
+	var cfg config
+	if err := db.GetAny("cfg", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 8080 {
+		t.Errorf("结构体解码结果不正确: %+v", cfg)
+	}
+
+	// 字符串按原样存储
+	if err := db.SetAny("name", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := db.GetS("name"); raw != "alice" {
+		t.Errorf("字符串应按原样存储，实际为%q", raw)
+	}
+	var name string
+	if err := db.GetAny("name", &name); err != nil {
+		t.Fatal(err)
+	}
+	if name != "alice" {
+		t.Errorf("字符串读取结果不正确: %s", name)
+	}
+}
+
+// TestGetMaybeCompressed 测试GetMaybeCompressed方法
+func TestGetMaybeCompressed(t *testing.T) {
+	db := newTestDB(t)
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("compressed value"))
+	zw.Close()
+
+	if err := db.Set("gz", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetS("raw", "raw value"); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := db.GetMaybeCompressed("gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "compressed value" {
+		t.Errorf("解压结果不正确: %s", val)
+	}
+
+	val, err = db.GetMaybeCompressed("raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "raw value" {
+		t.Errorf("未压缩数据应原样返回: %s", val)
+	}
+}
+
+// TestSetJSONIf 测试SetJSONIf方法
+func TestSetJSONIf(t *testing.T) {
+	db := newTestDB(t)
+
+	type record struct {
+		Name    string
+		Version int
+	}
+	newer := func(v record) func(old record, exists bool) bool {
+		return func(old record, exists bool) bool {
+			return !exists || v.Version > old.Version
+		}
+	}
+
+	v2 := record{Name: "b", Version: 2}
+	if ok, err := SetJSONIf(db, "rec", v2, newer(v2)); err != nil || !ok {
+		t.Fatalf("key不存在时应写入: %v, %v", ok, err)
+	}
+
+	v1 := record{Name: "a", Version: 1}
+	if ok, err := SetJSONIf(db, "rec", v1, newer(v1)); err != nil || ok {
+		t.Fatalf("版本较旧时不应写入: %v, %v", ok, err)
+	}
+
+	var got record
+	if err := db.GetAny("rec", &got); err != nil || got != v2 {
+		t.Errorf("存储的值不正确: %+v, %v", got, err)
+	}
+}
+
+// TestSetJSONGetJSON 测试SetJSON和GetJSON方法
+func TestSetJSONGetJSON(t *testing.T) {
+	db := newTestDB(t)
+
+	type config struct {
+		Port int
+		Name string
+	}
+	if err := db.SetJSON("cfg", config{Port: 8080, Name: "app"}); err != nil {
+		t.Fatal(err)
+	}
+	var got config
+	if err := db.GetJSON("cfg", &got); err != nil || got.Port != 8080 || got.Name != "app" {
+		t.Errorf("读取结果不正确: %+v, %v", got, err)
+	}
+
+	if err := db.GetJSON("missing", &got); !errors.Is(err, ErrNotFound) {
+		t.Errorf("key不存在时应返回ErrNotFound: %v", err)
+	}
+
+	db.SetS("bad", "not json")
+	err := db.GetJSON("bad", &got)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("应返回解码错误: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// RotateEncryptionKey 将数据库的主加密密钥更换为newKey
+// badger 使用主密钥加密保存在密钥注册表中的数据密钥，更换主密钥只需重写注册表，数据本身无需重写。
+// 注册表只能在数据库关闭时重写，因此该方法会依次：停止所有后台任务和计数器、关闭数据库、
+// 用当前密钥读取注册表并以newKey重新写入、再用newKey重新打开数据库。
+// 调用期间不能有其他读写操作，后台任务（如 StartGCThrottled）需要在之后重新启动，
+// 之前获取的 Counter 不能再使用，需要重新调用 NewCounter 获取。
+// newKey 的长度必须为16、24或32字节，且数据库必须已通过 badger.Options.WithEncryptionKey 开启加密；
+// 重写注册表失败时会用原密钥重新打开数据库。之后打开数据库时需要使用newKey
+// 示例：
+//
+//	opts := badger.DefaultOptions("./data").
+//	    WithEncryptionKey(oldKey).
+//	    WithIndexCacheSize(100 << 20)
+//	db, err := NewBadgerDBWithOptions(opts)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := db.RotateEncryptionKey(newKey); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) RotateEncryptionKey(newKey []byte) error {
+	switch len(newKey) {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("%w: key length %d", badger.ErrInvalidEncryptionKey, len(newKey))
+	}
+
+	opts := b.db.Opts()
+	if opts.InMemory {
+		return errors.New("rbadger: cannot rotate encryption key of in-memory database")
+	}
+	if len(opts.EncryptionKey) == 0 {
+		return errors.New("rbadger: database is not encrypted")
+	}
+
+	b.stopWorkers()
+	b.stopCounters()
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+
+	rotateErr := rewriteKeyRegistry(opts, newKey)
+	if rotateErr == nil {
+		opts.EncryptionKey = newKey
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return errors.Join(rotateErr, err)
+	}
+	b.db = db
+	b.resumeWorkers()
+	return rotateErr
+}
+
+// rewriteKeyRegistry 使用当前密钥读取密钥注册表，并以newKey重新加密写入
+func rewriteKeyRegistry(opts badger.Options, newKey []byte) error {
+	krOpts := badger.KeyRegistryOptions{
+		Dir:                           opts.Dir,
+		EncryptionKey:                 opts.EncryptionKey,
+		EncryptionKeyRotationDuration: opts.EncryptionKeyRotationDuration,
+	}
+	kr, err := badger.OpenKeyRegistry(krOpts)
+	if err != nil {
+		return err
+	}
+	defer kr.Close()
+
+	krOpts.EncryptionKey = newKey
+	return badger.WriteKeyRegistry(kr, krOpts)
+}
@@ -0,0 +1,83 @@
+package rbadger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestRotateEncryptionKey 测试更换加密密钥
+func TestRotateEncryptionKey(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := bytes.Repeat([]byte("a"), 32)
+	newKey := bytes.Repeat([]byte("b"), 32)
+
+	open := func(key []byte) (*BadgerDB, error) {
+		opts := badger.DefaultOptions(dir).
+			WithEncryptionKey(key).
+			WithIndexCacheSize(1 << 20).
+			WithLogger(nil)
+		return NewBadgerDBWithOptions(opts)
+	}
+
+	db, err := open(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetS("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.RotateEncryptionKey([]byte("short")); !errors.Is(err, badger.ErrInvalidEncryptionKey) {
+		t.Errorf("密钥长度不正确时应返回错误: %v", err)
+	}
+	if err := db.RotateEncryptionKey(newKey); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.GetS("k"); v != "v" {
+		t.Errorf("更换密钥后数据不正确: %q", v)
+	}
+	db.Close()
+
+	if _, err := open(oldKey); err == nil {
+		t.Error("更换后不应能用旧密钥打开")
+	}
+	db, err = open(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, _ := db.GetS("k"); v != "v" {
+		t.Errorf("用新密钥打开后数据不正确: %q", v)
+	}
+}
+
+// TestRotateEncryptionKeyCounter 测试更换密钥后计数器仍可使用
+func TestRotateEncryptionKeyCounter(t *testing.T) {
+	opts := badger.DefaultOptions(t.TempDir()).
+		WithEncryptionKey(bytes.Repeat([]byte("a"), 32)).
+		WithIndexCacheSize(1 << 20).
+		WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.NewCounter("views").Add(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RotateEncryptionKey(bytes.Repeat([]byte("b"), 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	views := db.NewCounter("views")
+	if err := views.Add(2); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := views.Get(); err != nil || n != 5 {
+		t.Errorf("更换密钥后计数器的值不正确: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,103 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+var (
+	// ErrNotFound key不存在。读取方法返回的该错误同时匹配 badger.ErrKeyNotFound，以兼容已有的判断
+	ErrNotFound = errors.New("rbadger: key not found")
+
+	// ErrResultTooLarge 扫描结果超过 WithMaxResultBytes 设置的大小上限
+	ErrResultTooLarge = errors.New("rbadger: result too large")
+
+	// ErrKeyOutsidePrefix 写入的key不在指定的前缀下
+	ErrKeyOutsidePrefix = errors.New("rbadger: key outside prefix")
+
+	// ErrValueTooLong 写入的值超过 WithMaxValueLen 设置的长度上限
+	ErrValueTooLong = errors.New("rbadger: value too long")
+
+	// ErrKeyExists 目标key已存在
+	ErrKeyExists = errors.New("rbadger: key already exists")
+
+	// ErrCorruptCacheEntry 带过期时间的key存储的数据无法解码为 CacheType，
+	// 通常是由于直接使用 Set 写入了原始数据。具体的key可通过 CorruptEntryError 获取
+	ErrCorruptCacheEntry = errors.New("rbadger: corrupt cache entry")
+
+	// ErrStopIteration 在 ForEach 等遍历回调中返回时停止遍历，不作为错误返回
+	ErrStopIteration = errors.New("rbadger: stop iteration")
+
+	// ErrInvalidInt 存储的数据不是 SetInt 写入的8字节整数
+	ErrInvalidInt = errors.New("rbadger: invalid int value")
+
+	// ErrInvalidFloat 存储的数据不是 SetFloat 写入的8字节浮点数
+	ErrInvalidFloat = errors.New("rbadger: invalid float value")
+
+	// ErrIntegerOverflow 计数器递增后的结果超出int64范围
+	ErrIntegerOverflow = errors.New("rbadger: integer overflow")
+
+	// ErrCounterOutOfRange 计数器递增后的结果超出 XIncrByWithLimit 指定的范围
+	ErrCounterOutOfRange = errors.New("rbadger: counter out of range")
+
+	// ErrInvalidHashKey 哈希的key中包含字段分隔符"\x00"
+	ErrInvalidHashKey = errors.New("rbadger: hash key contains separator")
+
+	// ErrInvalidZSetKey 有序集合的key中包含分隔符"\x01"或"\x02"
+	ErrInvalidZSetKey = errors.New("rbadger: sorted set key contains separator")
+
+	// ErrBackupInProgress 有备份正在进行，无法执行 DropAll
+	ErrBackupInProgress = errors.New("rbadger: backup in progress")
+)
+
+// CorruptEntryError 记录无法解码的key及解码错误
+// errors.Is(err, ErrCorruptCacheEntry) 对其返回true
+// 示例：
+//
+//	var ce *rbadger.CorruptEntryError
+//	if errors.As(err, &ce) {
+//	    log.Printf("key %s 数据已损坏: %v", ce.Key, ce.Err)
+//	}
+type CorruptEntryError struct {
+	Key string
+	Err error
+}
+
+func (e *CorruptEntryError) Error() string {
+	return fmt.Sprintf("%v: key %q: %v", ErrCorruptCacheEntry, e.Key, e.Err)
+}
+
+func (e *CorruptEntryError) Unwrap() error {
+	return e.Err
+}
+
+func (e *CorruptEntryError) Is(target error) bool {
+	return target == ErrCorruptCacheEntry
+}
+
+// notFoundError 包含key的 ErrNotFound
+type notFoundError struct {
+	key string
+}
+
+func (e *notFoundError) Error() string {
+	return fmt.Sprintf("%v: %q", ErrNotFound, e.key)
+}
+
+func (e *notFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == badger.ErrKeyNotFound
+}
+
+// notFound 将 badger.ErrKeyNotFound 转换为包含key的 ErrNotFound，其他错误原样返回
+func notFound(key string, err error) error {
+	if err == badger.ErrKeyNotFound {
+		return &notFoundError{key: key}
+	}
+	return err
+}
@@ -0,0 +1,767 @@
+package rbadger
+
+import (
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// expireBatchSize 批量删除过期key时每个事务处理的最大key数量
+const expireBatchSize = 1000
+
+// cacheMagic 带版本的 CacheType 存储格式的头部，其后的一个字节为格式版本
+// gob 与 JSON 编码的数据都不会以0x00开头，因此可以与不带版本的旧数据区分
+var cacheMagic = []byte{0x00, 0xcb}
+
+// cacheVersion 当前 CacheType 存储格式的版本
+// 版本1：头部之后为编解码器编码的 CacheType
+const cacheVersion = 1
+
+// encodeCache 使用配置的编解码器将 CacheType 编码为带版本头部的存储格式
+func (b *BadgerDB) encodeCache(cache CacheType) ([]byte, error) {
+	data, err := b.cfg.codec.Marshal(cache)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(cacheMagic)+1+len(data))
+	buf = append(buf, cacheMagic...)
+	buf = append(buf, cacheVersion)
+	return append(buf, data...), nil
+}
+
+// decodeCache 使用配置的编解码器将存储的数据解码为 CacheType
+// 不带版本头部的旧数据按原样解码，不支持的版本返回错误
+func (b *BadgerDB) decodeCache(val []byte) (CacheType, error) {
+	var cache CacheType
+	if bytes.HasPrefix(val, cacheMagic) && len(val) > len(cacheMagic) {
+		version := val[len(cacheMagic)]
+		if version != cacheVersion {
+			return cache, fmt.Errorf("rbadger: unsupported cache version %d", version)
+		}
+		val = val[len(cacheMagic)+1:]
+	}
+	err := b.cfg.codec.Unmarshal(val, &cache)
+	return cache, err
+}
+
+// handleCorrupt 在开启 WithDeleteCorrupt 时删除无法解码的key，并原样返回err
+func (b *BadgerDB) handleCorrupt(key string, err error) error {
+	if b.cfg.deleteCorrupt && errors.Is(err, ErrCorruptCacheEntry) {
+		b.DelBytes([]byte(key))
+	}
+	return err
+}
+
+// expiredAt 判断缓存在指定时间点(Unix秒)是否已过期
+func (c CacheType) expiredAt(now int64) bool {
+	return c.Expire > 0 && c.Expire <= now
+}
+
+// sliding 判断缓存是否需要在读取时顺延过期时间
+// 已通过 XPersist 等方式清除过期时间的key不再滑动
+func (c CacheType) sliding() bool {
+	return c.Sliding && c.TTLSeconds > 0 && c.Expire > 0
+}
+
+// slideExpire 将滑动过期的缓存的过期时间重新设置为now加上TTLSeconds
+func slideExpire(cache *CacheType, now time.Time) {
+	if cache.sliding() {
+		cache.Expire = now.Unix() + cache.TTLSeconds
+	}
+}
+
+// DeleteExpiredNow 立即扫描指定前缀下带过期时间存储的key，并删除所有已过期的key
+// 返回删除的key数量。扫描在只读事务中完成，每发现 expireBatchSize 个过期key就在独立的写事务中删除一批，
+// 删除前会再次确认key仍处于过期状态，避免误删期间被重新写入的数据；包内部保留的key不会被检查
+// 示例：
+//
+//	n, err := db.DeleteExpiredNow("cache:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("清理了%d个过期key\n", n)
+func (b *BadgerDB) DeleteExpiredNow(prefix string) (int, error) {
+	var expiredKeys [][]byte
+	deleted := 0
+
+	now := time.Now().Unix()
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		opts.PrefetchValues = false // 内部保留的key无需读取值
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err != nil {
+					// 非CacheType数据，跳过
+					return nil
+				}
+				if cache.expiredAt(now) {
+					expiredKeys = append(expiredKeys, item.KeyCopy(nil))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			// 每攒满一批就删除，内存占用不随过期key的数量增长
+			if len(expiredKeys) >= expireBatchSize {
+				n, err := b.deleteExpiredKeys(expiredKeys)
+				deleted += n
+				if err != nil {
+					return err
+				}
+				expiredKeys = expiredKeys[:0]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return deleted, err
+	}
+
+	n, err := b.deleteExpiredKeys(expiredKeys)
+	return deleted + n, err
+}
+
+// reaperWorker 过期key后台清理任务的名称
+const reaperWorker = "reaper"
+
+// StartExpiryReaper 启动后台清理，每隔interval扫描整个数据库并删除所有已过期的缓存数据
+// 只在读取时删除过期key会使不再被访问的过期数据一直占用磁盘，后台清理可以回收这部分空间。
+// 每轮的清理数量可通过 OnReap 获取。与 RunGC 及后台垃圾回收互不影响，
+// 已在运行时重复调用不会生效；Close 时会自动停止
+// 示例：
+//
+//	db.OnReap(func(n int, err error) {
+//	    log.Printf("清理了%d个过期key, err=%v", n, err)
+//	})
+//	db.StartExpiryReaper(10 * time.Minute)
+//	defer db.StopExpiryReaper()
+func (b *BadgerDB) StartExpiryReaper(interval time.Duration) {
+	b.startWorker(reaperWorker, func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			n, err := b.DeleteExpiredNow("")
+			b.hookMu.RLock()
+			fn := b.onReap
+			b.hookMu.RUnlock()
+			if fn != nil {
+				fn(n, err)
+			}
+		}
+	})
+}
+
+// StopExpiryReaper 停止后台清理，并等待正在进行的清理结束
+func (b *BadgerDB) StopExpiryReaper() {
+	b.stopWorker(reaperWorker)
+}
+
+// OnReap 设置后台清理每轮结束时的回调，n为本轮删除的过期key数量，err为本轮遇到的错误
+// 回调在后台清理的goroutine中同步执行；传入nil可取消回调
+// 示例：
+//
+//	db.OnReap(func(n int, err error) {
+//	    reapedTotal.Add(float64(n))
+//	})
+func (b *BadgerDB) OnReap(fn func(n int, err error)) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.onReap = fn
+}
+
+// deleteExpiredKeys 分批删除给定的key，删除前在同一事务中确认其仍已过期
+func (b *BadgerDB) deleteExpiredKeys(keys [][]byte) (int, error) {
+	deleted := 0
+	for start := 0; start < len(keys); start += expireBatchSize {
+		end := start + expireBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var removed [][]byte
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			removed = removed[:0]
+			now := time.Now().Unix()
+			for _, key := range keys[start:end] {
+				item, err := txn.Get(key)
+				if err == badger.ErrKeyNotFound {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+
+				var expired bool
+				err = item.Value(func(val []byte) error {
+					cache, err := b.decodeCache(val)
+					if err != nil {
+						return nil
+					}
+					expired = cache.expiredAt(now)
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				if !expired {
+					continue
+				}
+
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+				removed = append(removed, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += len(removed)
+		b.noteDeletes(len(removed))
+		for _, key := range removed {
+			b.invalidate(string(key))
+		}
+		b.fireExpire(removed...)
+	}
+	return deleted, nil
+}
+
+const (
+	expireWorker        = "expire"               // 过期key删除后台任务的名称
+	expireQueueSize     = 4096                   // 等待删除的过期key队列长度
+	expireFlushInterval = 500 * time.Millisecond // 后台任务批量删除过期key的间隔
+)
+
+// expireKeys 将读取时发现的过期key交给后台任务删除，使读取路径不产生写事务
+// 后台任务在第一次需要时启动；队列已满时丢弃，这些key会在之后的读取或主动清理时再被删除
+func (b *BadgerDB) expireKeys(keys ...string) {
+	b.enqueueExpired(b.expired, keys)
+}
+
+// expireIndexedKeys 与 expireKeys 相同，用于由 SetExIndexed 存储的key，删除时同时删除其过期时间索引
+func (b *BadgerDB) expireIndexedKeys(keys ...string) {
+	b.enqueueExpired(b.expiredIndexed, keys)
+}
+
+// enqueueExpired 将过期key放入指定队列，队列已满时丢弃剩余的key
+func (b *BadgerDB) enqueueExpired(queue chan string, keys []string) {
+	if len(keys) == 0 || b.db.IsClosed() {
+		return
+	}
+	b.startWorker(expireWorker, b.runExpireWorker)
+
+	for _, key := range keys {
+		select {
+		case queue <- key:
+		default:
+			return
+		}
+	}
+}
+
+// runExpireWorker 收集队列中的过期key，每隔 expireFlushInterval 或攒满一批时在一个事务中删除
+// 停止时删除队列中剩余的key后退出
+func (b *BadgerDB) runExpireWorker(stop <-chan struct{}) {
+	ticker := time.NewTicker(expireFlushInterval)
+	defer ticker.Stop()
+
+	pending := make(map[string]struct{})
+	pendingIndexed := make(map[string]struct{})
+	flush := func() {
+		if len(pending) > 0 {
+			b.deleteExpiredKeys(pendingKeys(pending))
+		}
+		if len(pendingIndexed) > 0 {
+			b.deleteExpiredIndexed(pendingKeys(pendingIndexed))
+		}
+	}
+
+	for {
+		select {
+		case <-stop:
+			for {
+				select {
+				case key := <-b.expired:
+					pending[key] = struct{}{}
+				case key := <-b.expiredIndexed:
+					pendingIndexed[key] = struct{}{}
+				default:
+					flush()
+					return
+				}
+			}
+		case key := <-b.expired:
+			pending[key] = struct{}{}
+			if len(pending) >= expireBatchSize {
+				flush()
+			}
+		case key := <-b.expiredIndexed:
+			pendingIndexed[key] = struct{}{}
+			if len(pendingIndexed) >= expireBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// pendingKeys 取出集合中的全部key并清空集合
+func pendingKeys(pending map[string]struct{}) [][]byte {
+	keys := make([][]byte, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, []byte(key))
+	}
+	clear(pending)
+	return keys
+}
+
+// OnExpire 设置过期key被删除时的回调
+// 无论是读取时的惰性删除还是主动清理，每个被删除的过期key都会触发一次回调。
+// 回调在独立的goroutine中异步执行，不会阻塞读取；传入nil可取消回调
+// 示例：
+//
+//	db.OnExpire(func(key string) {
+//	    log.Printf("key已过期: %s", key)
+//	})
+func (b *BadgerDB) OnExpire(fn func(key string)) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.onExpire = fn
+}
+
+// fireExpire 异步触发过期回调
+func (b *BadgerDB) fireExpire(keys ...[]byte) {
+	b.hookMu.RLock()
+	fn := b.onExpire
+	b.hookMu.RUnlock()
+
+	if fn == nil {
+		return
+	}
+	for _, key := range keys {
+		go fn(string(key))
+	}
+}
+
+// XExpireNX 仅当key未设置过期时间时为其设置过期时间
+// 返回值表示是否设置成功，已有过期时间的key保持不变
+// 该方法是并发安全的
+// 示例：
+//
+//	ok, err := db.XExpireNX("key", time.Hour)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !ok {
+//	    fmt.Println("key已设置过期时间，未做修改")
+//	}
+func (b *BadgerDB) XExpireNX(key string, expires time.Duration) (bool, error) {
+	defer b.invalidate(key)
+
+	applied := false
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		var cache CacheType
+		err = item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if cache.expiredAt(now.Unix()) {
+			return badger.ErrKeyNotFound
+		}
+		// 已设置过期时间，不覆盖
+		if cache.Expire > 0 {
+			return nil
+		}
+
+		cache.Expire = now.Add(expires).Unix()
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		applied = true
+		return txn.Set([]byte(key), data)
+	})
+	if err != nil {
+		return false, notFound(key, err)
+	}
+	return applied, nil
+}
+
+// XPersist 清除key的过期时间，使其永不过期
+// key不存在或已过期时返回 ErrNotFound，未设置过期时间的key保持不变
+// 示例：
+//
+//	if err := db.XPersist("cache:report"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XPersist(key string) error {
+	defer b.invalidate(key)
+
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		var cache CacheType
+		err = item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if cache.expiredAt(time.Now().Unix()) {
+			return badger.ErrKeyNotFound
+		}
+		if cache.Expire == 0 {
+			return nil
+		}
+
+		cache.Expire = 0
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
+	})
+	return notFound(key, err)
+}
+
+// XGetTouch 获取带过期时间的缓存数据，并将未过期key的过期时间顺延为当前时间加上extend
+// 读取、过期检查与过期时间更新在同一个事务中完成，已过期的key会被删除并返回nil，
+// 未设置过期时间的key保持永不过期
+// 该方法是并发安全的
+// 示例：
+//
+//	value, err := db.XGetTouch("session:1", 30*time.Minute)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("key不存在或已过期")
+//	}
+func (b *BadgerDB) XGetTouch(key string, extend time.Duration) ([]byte, error) {
+	return b.xgetAndSetExpire(key, func(cache *CacheType, now time.Time) {
+		if cache.Expire > 0 {
+			cache.Expire = now.Add(extend).Unix()
+		}
+	})
+}
+
+// XGetEx 获取带过期时间的缓存数据，并将过期时间重新设置为当前时间加上ttl，适用于滑动过期的缓存
+// ttl为0时保持原有的过期时间不变，ttl为负数时清除过期时间使key永不过期
+// 读取、过期检查与过期时间更新在同一个事务中完成，已过期的key会被删除并返回nil
+// 与 XGetTouch 不同，未设置过期时间的key也会被设置过期时间
+// 示例：
+//
+//	value, err := db.XGetEx("session:1", 30*time.Minute)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("会话不存在或已过期")
+//	}
+func (b *BadgerDB) XGetEx(key string, ttl time.Duration) ([]byte, error) {
+	return b.xgetAndSetExpire(key, func(cache *CacheType, now time.Time) {
+		switch {
+		case ttl > 0:
+			cache.Expire = now.Add(ttl).Unix()
+		case ttl < 0:
+			cache.Expire = 0
+		}
+	})
+}
+
+// XTouch 将key的过期时间重新设置为当前时间加上ttl，不返回key的值，返回key是否存在
+// key不存在或已过期时返回false，已过期的key会被删除。适用于对较大的值做保活，避免把值传回调用方
+// ttl必须大于0，否则返回错误且不做修改；需要清除过期时间时应使用 XPersist
+// 示例：
+//
+//	ok, err := db.XTouch("blob:1", time.Hour)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !ok {
+//	    fmt.Println("key不存在或已过期")
+//	}
+func (b *BadgerDB) XTouch(key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		return false, errors.New("rbadger: touch ttl must be positive")
+	}
+	value, err := b.xgetAndSetExpire(key, func(cache *CacheType, now time.Time) {
+		cache.Expire = now.Add(ttl).Unix()
+	})
+	if err != nil {
+		return false, err
+	}
+	return value != nil, nil
+}
+
+// xgetAndSetExpire 在一个写事务中读取未过期的缓存数据，并通过update修改其过期时间
+// 过期时间没有变化时不写入；已过期的key会被删除并返回nil
+func (b *BadgerDB) xgetAndSetExpire(key string, update func(cache *CacheType, now time.Time)) ([]byte, error) {
+	defer b.invalidate(key)
+
+	var valCopy []byte
+	var expired bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		valCopy, expired = nil, false
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		var cache CacheType
+		err = item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if cache.expiredAt(now.Unix()) {
+			expired = true
+			return txn.Delete([]byte(key))
+		}
+
+		valCopy = cache.Data
+		if valCopy == nil {
+			valCopy = []byte{}
+		}
+
+		oldExpire := cache.Expire
+		update(&cache, now)
+		if cache.Expire == oldExpire {
+			return nil
+		}
+
+		data, err := b.encodeCache(cache)
+		if err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), data)
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		b.fireExpire([]byte(key))
+		return nil, nil
+	}
+	return valCopy, nil
+}
+
+// KeyTTL 表示key及其剩余生存时间(秒)
+type KeyTTL struct {
+	Key string
+	TTL int64
+}
+
+// keyTTLHeap 按剩余生存时间排序的最大堆，用于保留最早过期的limit个key
+type keyTTLHeap []KeyTTL
+
+func (h keyTTLHeap) Len() int           { return len(h) }
+func (h keyTTLHeap) Less(i, j int) bool { return h[i].TTL > h[j].TTL }
+func (h keyTTLHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyTTLHeap) Push(x any)        { *h = append(*h, x.(KeyTTL)) }
+func (h *keyTTLHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// XKeysByExpiry 返回指定前缀下最早过期的limit个未过期key，按剩余生存时间升序排列
+// 未设置过期时间的key和已过期的key不会被返回，扫描过程中仅保留limit个候选，内存占用有界
+// 示例：
+//
+//	items, err := db.XKeysByExpiry("cache:", 100)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, item := range items {
+//	    fmt.Printf("%s 剩余%d秒\n", item.Key, item.TTL)
+//	}
+func (b *BadgerDB) XKeysByExpiry(prefix string, limit int) ([]KeyTTL, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	h := make(keyTTLHeap, 0, limit)
+	now := time.Now().Unix()
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			err := item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err != nil || cache.Expire == 0 || cache.expiredAt(now) {
+					return nil
+				}
+
+				ttl := cache.Expire - now
+				if h.Len() < limit {
+					heap.Push(&h, KeyTTL{Key: string(item.Key()), TTL: ttl})
+				} else if ttl < h[0].TTL {
+					h[0] = KeyTTL{Key: string(item.Key()), TTL: ttl}
+					heap.Fix(&h, 0)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]KeyTTL, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(&h).(KeyTTL)
+	}
+	return result, nil
+}
+
+// XGetAllowStale 获取带过期时间的缓存数据，即使已过期也返回其值，且不会删除该key
+// expired 表示该值是否已过期，key不存在时返回 nil, false, nil
+// 适用于"过期后继续提供旧值，同时异步刷新"的场景
+// 示例：
+//
+//	value, expired, err := db.XGetAllowStale("key")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if expired {
+//	    go refresh("key")
+//	}
+func (b *BadgerDB) XGetAllowStale(key string) (value []byte, expired bool, err error) {
+	err = b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			cache, err := b.decodeCache(val)
+			if err != nil {
+				return err
+			}
+			expired = cache.expiredAt(time.Now().Unix())
+			value = append([]byte{}, cache.Data...)
+			return nil
+		})
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, expired, nil
+}
+
+// XGetMeta 获取带过期时间的缓存数据及其写入时间和过期时间
+// 未记录写入时间的旧数据返回零值时间，未设置过期时间时expire为零值时间；
+// key不存在或已过期时返回nil，并删除已过期的key
+// 示例：
+//
+//	value, created, expire, err := db.XGetMeta("key")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value != nil && !created.IsZero() {
+//	    fmt.Printf("数据已存在%v\n", time.Since(created))
+//	}
+func (b *BadgerDB) XGetMeta(key string) (value []byte, created time.Time, expire time.Time, err error) {
+	var cache CacheType
+	var expired bool
+	err = b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			cache, err = b.decodeCache(val)
+			if err != nil {
+				return err
+			}
+			expired = cache.expiredAt(time.Now().Unix())
+			return nil
+		})
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, time.Time{}, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	if expired {
+		b.expireKeys(key)
+		return nil, time.Time{}, time.Time{}, nil
+	}
+
+	value = cache.Data
+	if value == nil {
+		value = []byte{}
+	}
+	if cache.Created > 0 {
+		created = time.Unix(cache.Created, 0)
+	}
+	if cache.Expire > 0 {
+		expire = time.Unix(cache.Expire, 0)
+	}
+	return value, created, expire, nil
+}
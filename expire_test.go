@@ -0,0 +1,604 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestDeleteExpiredNow 测试DeleteExpiredNow方法
+func TestDeleteExpiredNow(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExSecS("cache:expired1", "v1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExSecS("cache:expired2", "v2", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExS("cache:valid", "v3", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetS("cache:plain", "v4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExSecS("other:expired", "v5", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 等待过期key过期
+	time.Sleep(2 * time.Second)
+
+	n, err := db.DeleteExpiredNow("cache:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("期望删除2个过期key，实际删除%d个", n)
+	}
+
+	keys, err := db.FindKeys("cache:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("期望剩余2个cache key，实际剩余%d个: %v", len(keys), keys)
+	}
+
+	// 其他前缀的过期key不受影响
+	if !db.Exists("other:expired") {
+		t.Error("其他前缀的key不应被删除")
+	}
+}
+
+// TestDeleteExpiredNowBatches 测试过期key超过一批时分批删除，且不检查内部保留的key
+func TestDeleteExpiredNowBatches(t *testing.T) {
+	db := newTestDB(t)
+
+	expired, err := db.encodeCache(CacheType{Data: []byte("v"), Expire: time.Now().Unix() - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := expireBatchSize*2 + 500
+	w := db.NewBulkWriter()
+	for i := 0; i < total; i++ {
+		if err := w.Set(fmt.Sprintf("cache:%05d", i), expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetMeta("expired", expired); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := db.DeleteExpiredNow("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != total {
+		t.Errorf("期望删除%d个过期key，实际删除%d个", total, n)
+	}
+	if keys, _ := db.FindKeys("cache:"); len(keys) != 0 {
+		t.Errorf("过期key应全部被删除，剩余%d个", len(keys))
+	}
+	if v, _ := db.GetMeta("expired"); v == nil {
+		t.Error("内部保留的key不应被删除")
+	}
+}
+
+// TestXExpireNX 测试XExpireNX方法
+func TestXExpireNX(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetS("permanent", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExS("pinned", "v2", 10*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := db.XExpireNX("permanent", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("未设置过期时间的key应该设置成功")
+	}
+	if ttl, _ := db.XTTL("permanent"); ttl <= 0 || ttl > 3600 {
+		t.Errorf("TTL不符合预期: %d", ttl)
+	}
+
+	ok, err = db.XExpireNX("pinned", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("已设置过期时间的key不应被覆盖")
+	}
+	if ttl, _ := db.XTTL("pinned"); ttl <= 3600 {
+		t.Errorf("原有的过期时间被缩短: %d", ttl)
+	}
+
+	if _, err := db.XExpireNX("missing", time.Hour); err == nil {
+		t.Error("不存在的key应该返回错误")
+	}
+}
+
+// TestXGetTouch 测试XGetTouch方法
+func TestXGetTouch(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExSecS("session", "data", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := db.XGetTouch("session", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "data" {
+		t.Errorf("值不正确: %s", val)
+	}
+	if ttl, _ := db.XTTL("session"); ttl <= 2 {
+		t.Errorf("过期时间应被顺延，实际TTL: %d", ttl)
+	}
+
+	val, err = db.XGetTouch("missing", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != nil {
+		t.Error("不存在的key应返回nil")
+	}
+}
+
+// TestXGetEx 测试XGetEx方法
+func TestXGetEx(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetS("session", "data"); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := db.XGetEx("session", time.Hour)
+	if err != nil || string(val) != "data" {
+		t.Fatalf("值不正确: %s, %v", val, err)
+	}
+	if ttl, _ := db.XTTL("session"); ttl <= 0 || ttl > 3600 {
+		t.Errorf("应设置过期时间，实际TTL: %d", ttl)
+	}
+
+	if _, err := db.XGetEx("session", 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := db.XTTL("session"); ttl <= 0 {
+		t.Errorf("ttl为0时应保持过期时间不变，实际TTL: %d", ttl)
+	}
+
+	if _, err := db.XGetEx("session", -1); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := db.XTTL("session"); ttl != -1 {
+		t.Errorf("ttl为负数时应清除过期时间，实际TTL: %d", ttl)
+	}
+
+	if err := db.XSetExS("old", "x", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if val, err := db.XGetEx("old", time.Hour); err != nil || val != nil {
+		t.Errorf("已过期的key应返回nil: %s, %v", val, err)
+	}
+	if db.Exists("old") {
+		t.Error("已过期的key应被删除")
+	}
+}
+
+// TestXPersist 测试XPersist方法
+func TestXPersist(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExS("tmp", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XPersist("tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := db.XTTL("tmp"); ttl != -1 {
+		t.Errorf("过期时间应被清除，实际TTL: %d", ttl)
+	}
+	if v, _ := db.XGetS("tmp"); v != "v" {
+		t.Errorf("值不应改变: %s", v)
+	}
+
+	// 未设置过期时间的key再次调用不报错
+	if err := db.XPersist("tmp"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.XPersist("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不存在的key应返回ErrNotFound: %v", err)
+	}
+	if err := db.XSetExS("old", "x", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XPersist("old"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("已过期的key应返回ErrNotFound: %v", err)
+	}
+}
+
+// TestXTouch 测试XTouch方法
+func TestXTouch(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExSecS("blob", "data", 2); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := db.XTouch("blob", time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("存在的key应返回true: %v, %v", ok, err)
+	}
+	if ttl, _ := db.XTTL("blob"); ttl <= 2 {
+		t.Errorf("过期时间应被顺延，实际TTL: %d", ttl)
+	}
+
+	if _, err := db.XTouch("blob", 0); err == nil {
+		t.Error("ttl<=0时应返回错误")
+	}
+	if v, _ := db.XGetS("blob"); v != "data" {
+		t.Errorf("ttl<=0时不应删除数据: %q", v)
+	}
+
+	if ok, err := db.XTouch("missing", time.Hour); err != nil || ok {
+		t.Errorf("不存在的key应返回false: %v, %v", ok, err)
+	}
+
+	if err := db.XSetExS("old", "x", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := db.XTouch("old", time.Hour); err != nil || ok {
+		t.Errorf("已过期的key应返回false: %v, %v", ok, err)
+	}
+}
+
+// TestXSetSliding 测试滑动过期的缓存在读取时顺延过期时间
+func TestXSetSliding(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetSliding("session", []byte("data"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	// 缩短剩余时间，模拟闲置了一段时间
+	if err := db.XExpire("session", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := db.XGetS("session"); err != nil || v != "data" {
+		t.Fatalf("值不正确: %s, %v", v, err)
+	}
+	if ttl, _ := db.XTTL("session"); ttl <= 10 {
+		t.Errorf("读取后过期时间应被顺延，实际TTL: %d", ttl)
+	}
+
+	// 普通key读取时过期时间不变
+	if err := db.XSetExS("plain", "data", 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	db.XGet("plain")
+	if ttl, _ := db.XTTL("plain"); ttl > 10 {
+		t.Errorf("普通key读取后过期时间不应改变，实际TTL: %d", ttl)
+	}
+
+	// 清除过期时间后不再滑动
+	if err := db.XPersist("session"); err != nil {
+		t.Fatal(err)
+	}
+	db.XGet("session")
+	if ttl, _ := db.XTTL("session"); ttl != -1 {
+		t.Errorf("XPersist后不应再设置过期时间，实际TTL: %d", ttl)
+	}
+
+	if err := db.XSetSliding("bad", []byte("x"), 0); err == nil {
+		t.Error("ttl不足一秒时应返回错误")
+	}
+}
+
+// TestXKeysByExpiry 测试XKeysByExpiry方法
+func TestXKeysByExpiry(t *testing.T) {
+	db := newTestDB(t)
+
+	ttls := map[string]time.Duration{
+		"c:a": 5 * time.Hour,
+		"c:b": 1 * time.Hour,
+		"c:c": 3 * time.Hour,
+		"c:d": 2 * time.Hour,
+	}
+	for key, ttl := range ttls {
+		if err := db.XSetExS(key, "v", ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.XSetS("c:permanent", "v"); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := db.XKeysByExpiry("c:", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"c:b", "c:d", "c:c"}
+	if len(items) != len(want) {
+		t.Fatalf("期望返回%d个key，实际返回%d个", len(want), len(items))
+	}
+	for i, item := range items {
+		if item.Key != want[i] {
+			t.Errorf("第%d个key期望为%s，实际为%s", i, want[i], item.Key)
+		}
+	}
+}
+
+// TestXGetAllowStale 测试XGetAllowStale方法
+func TestXGetAllowStale(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.XSetExSecS("stale", "old", 1); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+
+	val, expired, err := db.XGetAllowStale("stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "old" || !expired {
+		t.Errorf("期望返回过期的旧值，实际为%q, expired=%v", val, expired)
+	}
+	if !db.Exists("stale") {
+		t.Error("XGetAllowStale不应删除过期的key")
+	}
+}
+
+// TestOnExpire 测试过期回调
+func TestOnExpire(t *testing.T) {
+	db := newTestDB(t)
+
+	expiredCh := make(chan string, 4)
+	db.OnExpire(func(key string) {
+		expiredCh <- key
+	})
+
+	if err := db.XSetExSecS("k1", "v1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.XSetExS("k2", "v2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+
+	if val, _ := db.XGet("k1"); val != nil {
+		t.Fatal("k1应该已过期")
+	}
+	db.XGet("k2")
+	db.XGet("missing")
+
+	select {
+	case key := <-expiredCh:
+		if key != "k1" {
+			t.Errorf("回调的key不正确: %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未触发过期回调")
+	}
+
+	select {
+	case key := <-expiredCh:
+		t.Errorf("不应触发多余的回调: %s", key)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestXGetMeta 测试XGetMeta方法
+func TestXGetMeta(t *testing.T) {
+	db := newTestDB(t)
+
+	before := time.Now().Unix()
+	if err := db.XSetExS("k", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	value, created, expire, err := db.XGetMeta("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "v" {
+		t.Errorf("值不正确: %s", value)
+	}
+	if created.Unix() < before || created.Unix() > time.Now().Unix() {
+		t.Errorf("写入时间不正确: %v", created)
+	}
+	if expire.Sub(created) < 59*time.Minute {
+		t.Errorf("过期时间不正确: %v", expire)
+	}
+
+	// 旧格式的数据没有写入时间
+	data, err := db.encodeCache(CacheType{Data: []byte("old")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set("legacy", data); err != nil {
+		t.Fatal(err)
+	}
+	value, created, expire, err = db.XGetMeta("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "old" || !created.IsZero() || !expire.IsZero() {
+		t.Errorf("旧数据结果不正确: %s, %v, %v", value, created, expire)
+	}
+}
+
+// TestXGetCorrupt 测试XGet读取到无法解码的数据
+func TestXGetCorrupt(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetS("raw", "not gob"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := db.XGet("raw")
+	var ce *CorruptEntryError
+	if !errors.Is(err, ErrCorruptCacheEntry) || !errors.As(err, &ce) || ce.Key != "raw" {
+		t.Fatalf("应返回包含key的ErrCorruptCacheEntry: %v", err)
+	}
+	if !db.Exists("raw") {
+		t.Error("默认不应删除损坏的key")
+	}
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	healing, err := NewBadgerDBWithOptions(opts, WithDeleteCorrupt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer healing.Close()
+
+	healing.SetS("raw", "not gob")
+	if _, err := healing.XTTL("raw"); !errors.Is(err, ErrCorruptCacheEntry) {
+		t.Errorf("应返回ErrCorruptCacheEntry: %v", err)
+	}
+	if val, err := healing.XGet("raw"); val != nil || err != nil {
+		t.Errorf("损坏的key应已被删除: %q, %v", val, err)
+	}
+}
+
+// TestXGetWithFound 测试区分不存在、已过期与空值
+func TestXGetWithFound(t *testing.T) {
+	db := newTestDB(t)
+
+	db.XSetEx("empty", []byte{}, time.Hour)
+	if v, found, err := db.XGetWithFound("empty"); err != nil || !found || v == nil || len(v) != 0 {
+		t.Errorf("空值应返回found为true: %v, %v, %v", v, found, err)
+	}
+
+	if v, found, err := db.XGetWithFound("missing"); err != nil || found || v != nil {
+		t.Errorf("不存在的key应返回found为false: %v, %v, %v", v, found, err)
+	}
+
+	db.XSetExSecS("expired", "v", 1)
+	time.Sleep(1100 * time.Millisecond)
+	if _, found, _ := db.XGetWithFound("expired"); found {
+		t.Error("已过期的key应返回found为false")
+	}
+	if !waitDeleted(db, "expired") {
+		t.Error("已过期的key应被删除")
+	}
+}
+
+// TestExpireWorker 测试读取路径只读，过期key由后台任务批量删除，并在Close时处理完剩余的key
+func TestExpireWorker(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db")
+	db, err := NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		db.XSetExSecS(fmt.Sprintf("k:%d", i), "v", 1)
+	}
+	time.Sleep(1100 * time.Millisecond)
+
+	version := db.CurrentVersion()
+	for i := 0; i < 50; i++ {
+		if v, _ := db.XGet(fmt.Sprintf("k:%d", i)); v != nil {
+			t.Fatal("过期的key应返回nil")
+		}
+	}
+	if db.CurrentVersion() != version {
+		t.Error("读取过期key时不应同步写入")
+	}
+	if n := len(db.workers); n != 1 {
+		t.Errorf("期望1个后台任务，实际为%d个", n)
+	}
+
+	// Close 时后台任务删除队列中剩余的key后退出
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	db, err = NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, _ := db.CountKeys("k:"); n != 0 {
+		t.Errorf("过期的key应已被删除，剩余%d个", n)
+	}
+}
+
+// TestExpiryReaper 测试后台清理删除未被读取的过期key并通过回调报告数量
+func TestExpiryReaper(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 10; i++ {
+		db.XSetExSecS(fmt.Sprintf("cold:%d", i), "v", 1)
+	}
+	db.XSetExS("live", "v", time.Hour)
+	db.SetS("raw", "v")
+	time.Sleep(1100 * time.Millisecond)
+
+	reaped := make(chan int, 10)
+	db.OnReap(func(n int, err error) {
+		if err != nil {
+			t.Error(err)
+		}
+		reaped <- n
+	})
+	db.StartExpiryReaper(20 * time.Millisecond)
+	db.StartExpiryReaper(20 * time.Millisecond)
+	db.StartGC(time.Hour, 0.5)
+	if n := len(db.workers); n != 2 {
+		t.Errorf("期望2个后台任务，实际为%d个", n)
+	}
+
+	select {
+	case n := <-reaped:
+		if n != 10 {
+			t.Errorf("第一轮应清理10个key，实际为%d个", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("后台清理没有运行")
+	}
+	db.StopExpiryReaper()
+
+	if n, _ := db.CountKeys(""); n != 2 {
+		t.Errorf("未过期的数据不应被删除，剩余%d个key", n)
+	}
+	if n := len(db.workers); n != 1 {
+		t.Errorf("停止后台清理后应只剩垃圾回收任务，实际为%d个", n)
+	}
+}
+
+// TestXExists 测试XExists对逻辑上已过期但尚未删除的key返回false
+func TestXExists(t *testing.T) {
+	db := newTestDB(t)
+
+	db.XSetExSecS("k", "v", 1)
+	db.SetS("raw", "v")
+	if !db.XExists("k") {
+		t.Error("未过期的key应存在")
+	}
+	if db.XExists("raw") || db.XExists("missing") {
+		t.Error("非缓存数据或不存在的key应返回false")
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	// 先用Exists确认数据仍在磁盘上，再检查XExists
+	if !db.Exists("k") {
+		t.Fatal("过期的key此时应仍存储在数据库中")
+	}
+	if db.XExists("k") {
+		t.Error("已过期的key应返回false")
+	}
+}
@@ -0,0 +1,174 @@
+package rbadger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// exportRecord 导出格式中的一条记录，每条记录占一行JSON
+// key 和 value 以 base64 编码，以便无损保存二进制数据
+type exportRecord struct {
+	Key       []byte `json:"key"`
+	Value     []byte `json:"value"`
+	ExpiresAt uint64 `json:"expires_at,omitempty"`
+	UserMeta  byte   `json:"user_meta,omitempty"`
+	// IndexedExpire 由 SetExIndexed 记录在过期时间索引中的过期时间(Unix秒)
+	IndexedExpire int64 `json:"indexed_expire,omitempty"`
+}
+
+// ExportPrefixes 在同一个只读事务中导出多个前缀下的全部数据，保证各前缀处于同一时间点的一致快照
+// 输出为 JSON Lines 格式，每行一条记录，可通过 ImportPrefixes 导入。
+// 包内部保留的key不会被单独导出，但 SetExIndexed 记录在索引中的过期时间会随对应的key一起导出
+// 示例：
+//
+//	f, _ := os.Create("export.jsonl")
+//	defer f.Close()
+//	if err := db.ExportPrefixes(f, []string{"user:", "order:"}); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ExportPrefixes(w io.Writer, prefixes []string) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, prefix := range prefixes {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte(prefix)
+			it := txn.NewIterator(opts)
+
+			for it.Rewind(); it.Valid(); it.Next() {
+				item := it.Item()
+				if isReservedKey(item.Key()) {
+					continue
+				}
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					it.Close()
+					return err
+				}
+				expire, err := readTTLIndex(txn, string(item.Key()))
+				if err != nil {
+					it.Close()
+					return err
+				}
+				rec := exportRecord{
+					Key:           item.KeyCopy(nil),
+					Value:         value,
+					ExpiresAt:     item.ExpiresAt(),
+					UserMeta:      item.UserMeta(),
+					IndexedExpire: expire,
+				}
+				if err := enc.Encode(rec); err != nil {
+					it.Close()
+					return err
+				}
+			}
+			it.Close()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ImportPrefixes 导入由 ExportPrefixes 导出的数据，已存在的key会被覆盖
+// 记录中带有索引过期时间的key会同时恢复其过期时间索引，不带的key会清除已有的索引
+// 示例：
+//
+//	f, _ := os.Open("export.jsonl")
+//	defer f.Close()
+//	if err := db.ImportPrefixes(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ImportPrefixes(r io.Reader) error {
+	defer b.purgeReadCache()
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	dec := json.NewDecoder(r)
+	for {
+		var rec exportRecord
+		err := dec.Decode(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		e := badger.NewEntry(rec.Key, rec.Value).WithMeta(rec.UserMeta)
+		e.ExpiresAt = rec.ExpiresAt
+		if err := wb.SetEntry(e); err != nil {
+			return err
+		}
+
+		indexKey := ttlIndexKey(string(rec.Key))
+		if rec.IndexedExpire > 0 {
+			err = wb.Set(indexKey, []byte(strconv.FormatInt(rec.IndexedExpire, 10)))
+		} else {
+			err = wb.Delete(indexKey)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// loadMaxPendingWrites Load 时允许同时挂起的最大写入数量
+const loadMaxPendingWrites = 256
+
+// Backup 使用badger的备份功能，将版本号大于since的数据写入w，返回本次备份包含的最新版本号
+// 备份在只读事务中进行，无需停止服务。since为0时为全量备份；将上次返回的版本号作为下一次的since即可做增量备份，
+// 增量备份中包含删除标记，依次 Load 全量备份和各次增量备份即可恢复到最后一次备份时的状态
+// 示例：
+//
+//	f, _ := os.Create("full.bak")
+//	since, err := db.Backup(f, 0)
+//	f.Close()
+//	// ... 之后
+//	f, _ = os.Create("incr-1.bak")
+//	since, err = db.Backup(f, since)
+//	f.Close()
+func (b *BadgerDB) Backup(w io.Writer, since uint64) (uint64, error) {
+	b.backupMu.RLock()
+	defer b.backupMu.RUnlock()
+	return b.db.Backup(w, since)
+}
+
+// Load 导入由 Backup 生成的备份数据
+// 应在空数据库上执行（例如恢复到新创建的目录），导入期间不应有其他写入；
+// 恢复增量备份时需按备份的先后顺序依次 Load
+// 示例：
+//
+//	db, _ := rbadger.NewBadgerDB("./restore")
+//	f, _ := os.Open("full.bak")
+//	defer f.Close()
+//	if err := db.Load(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) Load(r io.Reader) error {
+	return b.Restore(r, loadMaxPendingWrites)
+}
+
+// Restore 与 Load 相同，但可以指定导入时允许同时挂起的最大写入数量，badger返回的错误会直接返回
+// maxPendingWrites 越大导入越快、占用内存越多，可在 NewBadgerDB 打开空目录后立即调用
+// 示例：
+//
+//	db, _ := rbadger.NewBadgerDB("./restore")
+//	f, _ := os.Open("full.bak")
+//	defer f.Close()
+//	if err := db.Restore(f, 1024); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) Restore(r io.Reader, maxPendingWrites int) error {
+	defer b.purgeReadCache()
+	return b.db.Load(r, maxPendingWrites)
+}
@@ -0,0 +1,178 @@
+package rbadger
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestExportImportPrefixes 测试多前缀导出与导入
+func TestExportImportPrefixes(t *testing.T) {
+	src := newTestDB(t)
+
+	data := map[string]string{
+		"user:1":  "alice",
+		"user:2":  "bob",
+		"order:1": "o1",
+		"other:1": "skip",
+	}
+	for key, value := range data {
+		if err := src.SetS(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := src.Set("user:bin", []byte{0x00, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.ExportPrefixes(&buf, []string{"user:", "order:"}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(buf.Bytes(), []byte("\n")); lines != 4 {
+		t.Errorf("期望导出4条记录，实际为%d条", lines)
+	}
+
+	dst := newTestDB(t)
+	if err := dst.ImportPrefixes(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := dst.FindKeys("")
+	if len(keys) != 4 {
+		t.Errorf("期望导入4个key，实际为%v", keys)
+	}
+	if val, _ := dst.GetS("user:1"); val != "alice" {
+		t.Errorf("user:1的值不正确: %s", val)
+	}
+	if val, _ := dst.Get("user:bin"); !bytes.Equal(val, []byte{0x00, 0xff}) {
+		t.Errorf("二进制值不正确: %v", val)
+	}
+}
+
+// TestExportImportIndexedTTL 测试导出导入时保留 SetExIndexed 的过期时间
+func TestExportImportIndexedTTL(t *testing.T) {
+	src := newTestDB(t)
+
+	if err := src.SetExIndexed("report:1", []byte("data"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetExIndexed("report:2", []byte("data"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SetS("report:3", "new"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestDB(t)
+	// 目标库中残留的索引不应影响导入的永不过期的值
+	if err := dst.SetExIndexed("report:3", []byte("old"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := src.ExportPrefixes(&buf, []string{"report:"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.ImportPrefixes(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	if v, _ := dst.GetExIndexed("report:1"); string(v) != "data" {
+		t.Errorf("未过期的key应被导入: %q", v)
+	}
+	if v, _ := dst.GetExIndexed("report:2"); v != nil {
+		t.Errorf("导入的key应保留过期时间: %q", v)
+	}
+	if v, _ := dst.GetExIndexed("report:3"); string(v) != "new" {
+		t.Errorf("导入不带过期时间的key时应清除已有的索引: %q", v)
+	}
+}
+
+// TestBackupLoad 测试全量与增量备份的恢复
+func TestBackupLoad(t *testing.T) {
+	src := newTestDB(t)
+	src.SetS("a", "1")
+	src.SetS("b", "2")
+
+	var full bytes.Buffer
+	since, err := src.Backup(&full, 0)
+	if err != nil || since == 0 {
+		t.Fatalf("全量备份失败: %d, %v", since, err)
+	}
+
+	src.SetS("a", "3")
+	src.Del("b")
+	var incr bytes.Buffer
+	if _, err := src.Backup(&incr, since); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestDB(t)
+	if err := dst.Load(&full); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := dst.GetS("b"); v != "2" {
+		t.Errorf("全量恢复结果不正确: %s", v)
+	}
+	if err := dst.Load(&incr); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := dst.GetS("a"); v != "3" {
+		t.Errorf("增量恢复结果不正确: %s", v)
+	}
+	if dst.Exists("b") {
+		t.Error("增量恢复后被删除的key不应存在")
+	}
+}
+
+// TestRestoreRoundTrip 测试备份后恢复到新目录的数据与原数据一致
+func TestRestoreRoundTrip(t *testing.T) {
+	src, err := NewBadgerDB(filepath.Join(t.TempDir(), "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	want := map[string]string{}
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("key:%03d", i)
+		want[key] = fmt.Sprintf("value-%d", i)
+		src.SetS(key, want[key])
+	}
+	src.XSetExS("cache", "x", time.Hour)
+	wantCache, _ := src.Get("cache")
+
+	var buf bytes.Buffer
+	if _, err := src.Backup(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := NewBadgerDB(filepath.Join(t.TempDir(), "dst"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if err := dst.Restore(&buf, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := dst.FindKeyValues("key:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("恢复后key数量不正确: %d", len(got))
+	}
+	for key, value := range want {
+		if string(got[key]) != value {
+			t.Errorf("key %s 恢复后的值不正确: %s", key, got[key])
+		}
+	}
+	if v, _ := dst.Get("cache"); !bytes.Equal(v, wantCache) {
+		t.Error("带过期时间的数据恢复后不一致")
+	}
+}
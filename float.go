@@ -0,0 +1,89 @@
+package rbadger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// SetFloat 以8字节大端序的IEEE-754二进制格式存储浮点数
+// 与 IncrByFloat 使用相同的编码，二者可以混合使用
+// 示例：
+//
+//	err := db.SetFloat("latency:avg", 12.5)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetFloat(key string, f float64) error {
+	return b.Set(key, encodeFloat(f))
+}
+
+// GetFloat 读取由 SetFloat/IncrByFloat 存储的浮点数
+// key不存在时返回 ErrNotFound，存储的数据不是8字节时返回 ErrInvalidFloat
+// 示例：
+//
+//	f, err := db.GetFloat("latency:avg")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetFloat(key string) (float64, error) {
+	data, err := b.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return decodeFloat(key, data)
+}
+
+// IncrByFloat 将以二进制格式存储的浮点数增加delta并返回新值，key不存在时从0开始
+// 读取与写入在同一个事务中完成，遇到并发冲突时自动重试
+// 示例：
+//
+//	total, err := db.IncrByFloat("latency:total", 3.2)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) IncrByFloat(key string, delta float64) (float64, error) {
+	defer b.invalidate(key)
+
+	var f float64
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		f = 0
+		item, err := txn.Get([]byte(key))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				f, err = decodeFloat(key, val)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		f += delta
+		return txn.Set([]byte(key), encodeFloat(f))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return f, nil
+}
+
+// encodeFloat 将浮点数编码为8字节大端序的IEEE-754格式
+func encodeFloat(f float64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(f))
+	return buf
+}
+
+// decodeFloat 解码8字节大端序的IEEE-754浮点数
+func decodeFloat(key string, data []byte) (float64, error) {
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%w: key %q has %d bytes", ErrInvalidFloat, key, len(data))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+}
@@ -0,0 +1,45 @@
+package rbadger
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestFloatHelpers 测试浮点数的存取与递增
+func TestFloatHelpers(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetFloat("f", 1.25); err != nil {
+		t.Fatal(err)
+	}
+	if f, err := db.GetFloat("f"); err != nil || f != 1.25 {
+		t.Errorf("读取结果不正确: %v, %v", f, err)
+	}
+	if f, err := db.IncrByFloat("f", 0.5); err != nil || f != 1.75 {
+		t.Errorf("递增结果不正确: %v, %v", f, err)
+	}
+
+	if _, err := db.GetFloat("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("key不存在时应返回ErrNotFound: %v", err)
+	}
+	db.SetS("bad", "1.5")
+	if _, err := db.IncrByFloat("bad", 1); !errors.Is(err, ErrInvalidFloat) {
+		t.Errorf("数据无效时应返回ErrInvalidFloat: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.IncrByFloat("sum", 0.5); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if f, _ := db.GetFloat("sum"); f != 10 {
+		t.Errorf("并发递增结果不正确: %v", f)
+	}
+}
@@ -0,0 +1,163 @@
+package rbadger
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// runGCCycle 连续运行值日志垃圾回收，直到没有可回收的文件为止
+// 没有可回收的数据时返回 nil
+func (b *BadgerDB) runGCCycle(discardRatio float64) error {
+	for {
+		err := b.db.RunValueLogGC(discardRatio)
+		if err == nil {
+			continue
+		}
+		if err == badger.ErrNoRewrite || err == badger.ErrRejected {
+			return nil
+		}
+		return err
+	}
+}
+
+// GCKey 重写指定key的值，使其写入当前的值日志文件，
+// 以便旧值日志文件中的该条目在后续垃圾回收中被回收
+// 原有的过期时间和元数据会被保留。这只是一个尽力而为的提示，
+// 旧文件是否被回收仍取决于 RunGC 时文件中的整体可回收比例
+// 示例：
+//
+//	if err := db.GCKey("hot:key"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	db.RunGC(0.5)
+func (b *BadgerDB) GCKey(key string) error {
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		val, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		e := badger.NewEntry([]byte(key), val).WithMeta(item.UserMeta())
+		e.ExpiresAt = item.ExpiresAt()
+		return txn.SetEntry(e)
+	})
+	return notFound(key, err)
+}
+
+// gcWorker 垃圾回收后台任务的名称
+const gcWorker = "gc"
+
+// StartGC 启动后台垃圾回收，每隔interval连续运行值日志回收，直到没有可回收的文件为止
+// 后台回收已在运行时重复调用不会生效；Close 时会自动停止
+// 示例：
+//
+//	db.StartGC(10*time.Minute, 0.5)
+//	defer db.StopGC()
+func (b *BadgerDB) StartGC(interval time.Duration, discardRatio float64) {
+	b.StartGCThrottled(interval, discardRatio, 0)
+}
+
+// StartGCThrottled 启动后台垃圾回收，每隔interval运行一轮值日志回收
+// 每轮中连续回收直到没有可回收的文件为止，相邻两次回收之间暂停pause，
+// 以降低回收对磁盘I/O的占用，避免影响前台读写的延迟
+// 后台回收已在运行时重复调用不会生效；Close 时会自动停止
+// 示例：
+//
+//	db.StartGCThrottled(10*time.Minute, 0.5, 2*time.Second)
+//	defer db.StopGC()
+func (b *BadgerDB) StartGCThrottled(interval time.Duration, discardRatio float64, pause time.Duration) {
+	b.startWorker(gcWorker, func(stop <-chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			for b.db.RunValueLogGC(discardRatio) == nil {
+				select {
+				case <-stop:
+					return
+				case <-time.After(pause):
+				}
+			}
+		}
+	})
+}
+
+// StopGC 停止后台垃圾回收，并等待正在进行的回收结束
+func (b *BadgerDB) StopGC() {
+	b.stopWorker(gcWorker)
+}
+
+const (
+	adaptiveGCMinInterval = 30 * time.Second // 有可回收数据时的检查间隔
+	adaptiveGCMaxInterval = 30 * time.Minute // 退避后的最大检查间隔
+)
+
+// StartAdaptiveGC 启动自适应的后台垃圾回收
+// 从 adaptiveGCMinInterval 开始周期性地连续运行值日志回收，直到返回 ErrNoRewrite 为止：
+// 本轮回收到数据时恢复为最短间隔，没有可回收数据时间隔加倍，最长为 adaptiveGCMaxInterval。
+// 是否回收完全由 RunValueLogGC 的结果决定，不依赖 db.Size()（关闭Metrics时其结果恒为0）。
+// 这样数据基本静态时几乎不做无效回收，而写入突增后能及时回收空间
+// 与 StartGCThrottled 共用同一个后台任务，已有后台回收在运行时调用不会生效；Close 时会自动停止
+// 示例：
+//
+//	db.StartAdaptiveGC(0.5)
+//	defer db.StopGC()
+func (b *BadgerDB) StartAdaptiveGC(discardRatio float64) {
+	b.startWorker(gcWorker, func(stop <-chan struct{}) {
+		delay := adaptiveGCMinInterval
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-timer.C:
+			}
+
+			rewritten, stopped := b.adaptiveGCCycle(discardRatio, stop)
+			if stopped {
+				return
+			}
+			delay = nextAdaptiveGCDelay(delay, rewritten)
+			timer.Reset(delay)
+		}
+	})
+}
+
+// adaptiveGCCycle 连续运行值日志回收直到没有可回收的文件，返回是否回收到数据以及是否因stop而中止
+func (b *BadgerDB) adaptiveGCCycle(discardRatio float64, stop <-chan struct{}) (rewritten, stopped bool) {
+	for b.db.RunValueLogGC(discardRatio) == nil {
+		rewritten = true
+		select {
+		case <-stop:
+			return rewritten, true
+		default:
+		}
+	}
+	return rewritten, false
+}
+
+// nextAdaptiveGCDelay 回收到数据时返回最短间隔，否则将delay加倍，最长为 adaptiveGCMaxInterval
+func nextAdaptiveGCDelay(delay time.Duration, rewritten bool) time.Duration {
+	if rewritten {
+		return adaptiveGCMinInterval
+	}
+	if delay *= 2; delay > adaptiveGCMaxInterval {
+		delay = adaptiveGCMaxInterval
+	}
+	return delay
+}
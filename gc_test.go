@@ -0,0 +1,226 @@
+package rbadger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestStartGCThrottled 测试后台垃圾回收的启动与停止
+func TestStartGCThrottled(t *testing.T) {
+	dbPath := "./test_gc_db"
+	defer os.RemoveAll(dbPath)
+
+	db, err := NewBadgerDB(dbPath, WithNumCompactors(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.StartGCThrottled(50*time.Millisecond, 0.5, 10*time.Millisecond)
+	// 重复启动不会产生新的后台任务
+	db.StartGCThrottled(50*time.Millisecond, 0.5, 10*time.Millisecond)
+	if n := len(db.workers); n != 1 {
+		t.Errorf("期望1个后台任务，实际为%d个", n)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	db.StopGC()
+	if n := len(db.workers); n != 0 {
+		t.Errorf("停止后不应有后台任务，实际为%d个", n)
+	}
+
+	// Close 会停止仍在运行的后台任务
+	db.StartGCThrottled(50*time.Millisecond, 0.5, 10*time.Millisecond)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStartGC 测试StartGC与其他后台回收共用后台任务，且在Close时停止
+func TestStartGC(t *testing.T) {
+	db := newTestDB(t)
+
+	db.StartGC(10*time.Millisecond, 0.5)
+	db.StartGC(10*time.Millisecond, 0.5)
+	db.StartAdaptiveGC(0.5)
+	if n := len(db.workers); n != 1 {
+		t.Errorf("期望1个后台任务，实际为%d个", n)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	db.StopGC()
+	if n := len(db.workers); n != 0 {
+		t.Errorf("停止后不应有后台任务，实际为%d个", n)
+	}
+
+	db.StartGC(10*time.Millisecond, 0.5)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(db.workers); n != 0 {
+		t.Errorf("Close后不应有后台任务，实际为%d个", n)
+	}
+}
+
+// TestStartAdaptiveGC 测试自适应垃圾回收与 StartGCThrottled 共用后台任务
+func TestStartAdaptiveGC(t *testing.T) {
+	db := newTestDB(t)
+
+	db.StartAdaptiveGC(0.5)
+	db.StartGCThrottled(time.Minute, 0.5, 0)
+	if n := len(db.workers); n != 1 {
+		t.Errorf("期望1个后台任务，实际为%d个", n)
+	}
+
+	db.StopGC()
+	if n := len(db.workers); n != 0 {
+		t.Errorf("停止后不应有后台任务，实际为%d个", n)
+	}
+}
+
+// TestAdaptiveGCCycleWithoutMetrics 测试关闭Metrics时自适应回收仍能回收数据
+func TestAdaptiveGCCycleWithoutMetrics(t *testing.T) {
+	opts := badger.DefaultOptions(t.TempDir()).
+		WithMetricsEnabled(false).
+		WithLogger(nil).
+		WithValueThreshold(64).
+		WithValueLogFileSize(1 << 20).
+		WithMemTableSize(1 << 20).
+		WithNumLevelZeroTables(1).
+		WithNumLevelZeroTablesStall(2).
+		WithBaseTableSize(1 << 16).
+		WithBaseLevelSize(1 << 18)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// 反复覆盖写入，在值日志中产生可回收的旧值
+	val := make([]byte, 1024)
+	for round := 0; round < 10; round++ {
+		for i := 0; i < 3000; i++ {
+			if err := db.Set(fmt.Sprintf("k%d", i), val); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	rewritten, stopped := db.adaptiveGCCycle(0.5, make(chan struct{}))
+	if !rewritten || stopped {
+		t.Errorf("应回收到数据，实际rewritten=%v, stopped=%v", rewritten, stopped)
+	}
+
+	if d := nextAdaptiveGCDelay(adaptiveGCMaxInterval, true); d != adaptiveGCMinInterval {
+		t.Errorf("回收到数据后应恢复为最短间隔: %v", d)
+	}
+	if d := nextAdaptiveGCDelay(adaptiveGCMaxInterval, false); d != adaptiveGCMaxInterval {
+		t.Errorf("间隔不应超过最大值: %v", d)
+	}
+}
+
+// TestDeleteCompactionThreshold 测试累计删除达到阈值时触发压缩
+func TestDeleteCompactionThreshold(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts, WithDeleteCompactionThreshold(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 3; i++ {
+		key := fmt.Sprintf("k%d", i)
+		db.SetS(key, "v")
+		if err := db.Del(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := db.deletes.Load(); n != 0 {
+		t.Errorf("触发压缩后计数应清零，实际为%d", n)
+	}
+
+	// 压缩完成后任务会自行移除
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		db.workersMu.Lock()
+		n := len(db.workers)
+		db.workersMu.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("压缩任务未结束")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestShutdown 测试优雅关闭
+func TestShutdown(t *testing.T) {
+	dbPath := "./test_shutdown_db"
+	defer os.RemoveAll(dbPath)
+
+	db, err := NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.StartGCThrottled(time.Hour, 0.5, 0)
+	if err := db.SetS("k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v, _ := db.GetS("k"); v != "v" {
+		t.Errorf("重新打开后数据不正确: %q", v)
+	}
+}
+
+// TestShutdownTimeout 测试后台任务未能及时退出时返回ctx的错误
+func TestShutdownTimeout(t *testing.T) {
+	db, err := NewBadgerDBWithOptions(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	db.startWorker("blocking", func(stop <-chan struct{}) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := db.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("应返回超时错误: %v", err)
+	}
+}
+
+// TestNoWorkerAfterClose 测试关闭后不会再启动后台任务
+func TestNoWorkerAfterClose(t *testing.T) {
+	db, err := NewBadgerDBWithOptions(badger.DefaultOptions("").WithInMemory(true).WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.startWorker(expireWorker, db.runExpireWorker) {
+		t.Error("关闭后不应启动后台任务")
+	}
+	db.StartGC(time.Hour, 0.5)
+	if n := len(db.workers); n != 0 {
+		t.Errorf("关闭后不应有后台任务，实际为%d个", n)
+	}
+}
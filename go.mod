@@ -2,7 +2,10 @@ module github.com/leafney/rose-badger
 
 go 1.23.8
 
-require github.com/dgraph-io/badger/v4 v4.7.0
+require (
+	github.com/dgraph-io/badger/v4 v4.7.0
+	golang.org/x/sync v0.9.0
+)
 
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
@@ -0,0 +1,112 @@
+package rbadger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// hashSep 哈希的key与字段名之间的分隔符
+// 每个字段存储为独立的key：key + "\x00" + field。key中不能包含该分隔符，
+// 因此第一个分隔符之后的部分都属于字段名，字段名可以包含任意字节
+const hashSep = "\x00"
+
+// hashFieldKey 返回哈希字段对应的存储key
+func hashFieldKey(key, field string) ([]byte, error) {
+	if strings.Contains(key, hashSep) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidHashKey, key)
+	}
+	return []byte(key + hashSep + field), nil
+}
+
+// HSet 设置哈希key中指定字段的值
+// key中不能包含"\x00"，否则返回 ErrInvalidHashKey
+// 示例：
+//
+//	err := db.HSet("user:1", "name", []byte("Tom"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) HSet(key, field string, value []byte) error {
+	k, err := hashFieldKey(key, field)
+	if err != nil {
+		return err
+	}
+	return b.SetBytes(k, value)
+}
+
+// HGet 获取哈希key中指定字段的值，字段不存在时返回 ErrNotFound
+// 示例：
+//
+//	name, err := db.HGet("user:1", "name")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) HGet(key, field string) ([]byte, error) {
+	k, err := hashFieldKey(key, field)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetBytes(k)
+}
+
+// HGetAll 获取哈希key中的所有字段及其值，哈希不存在时返回空map
+// 在一个只读事务中扫描 key+"\x00" 前缀，返回结果中的字段名已去掉前缀
+// 示例：
+//
+//	fields, err := db.HGetAll("user:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for field, value := range fields {
+//	    fmt.Printf("%s: %s\n", field, value)
+//	}
+func (b *BadgerDB) HGetAll(key string) (map[string][]byte, error) {
+	prefix, err := hashFieldKey(key, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte)
+	err = b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			result[string(item.Key()[len(prefix):])] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// HDel 在一个事务中删除哈希key中的指定字段，不存在的字段会被忽略
+// 示例：
+//
+//	err := db.HDel("user:1", "name", "age")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) HDel(key string, fields ...string) error {
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		k, err := hashFieldKey(key, field)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, string(k))
+	}
+	_, err := b.MDel(keys...)
+	return err
+}
@@ -0,0 +1,45 @@
+package rbadger
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestHash 测试哈希的读写与删除
+func TestHash(t *testing.T) {
+	db := newTestDB(t)
+
+	db.HSet("user:1", "name", []byte("Tom"))
+	db.HSet("user:1", "age", []byte("18"))
+	db.HSet("user:1", "a\x00b", []byte("sep"))
+	db.HSet("user:10", "name", []byte("Jerry"))
+
+	if v, err := db.HGet("user:1", "name"); err != nil || string(v) != "Tom" {
+		t.Errorf("HGet结果不正确: %s, %v", v, err)
+	}
+	if _, err := db.HGet("user:1", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("字段不存在时应返回ErrNotFound: %v", err)
+	}
+
+	all, err := db.HGetAll("user:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || string(all["age"]) != "18" || string(all["a\x00b"]) != "sep" {
+		t.Errorf("HGetAll结果不正确: %v", all)
+	}
+
+	if err := db.HDel("user:1", "name", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := db.HGetAll("user:1"); len(all) != 2 {
+		t.Errorf("删除后字段数量不正确: %v", all)
+	}
+	if all, _ := db.HGetAll("none"); len(all) != 0 {
+		t.Errorf("不存在的哈希应返回空结果: %v", all)
+	}
+
+	if err := db.HSet("bad\x00key", "f", nil); !errors.Is(err, ErrInvalidHashKey) {
+		t.Errorf("key包含分隔符时应返回ErrInvalidHashKey: %v", err)
+	}
+}
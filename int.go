@@ -0,0 +1,94 @@
+package rbadger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// SetInt 以8字节大端序二进制格式存储整数
+// 比 XIncrBy 使用的十进制字符串加 gob 编码更紧凑，读取时也无需解析
+// 示例：
+//
+//	err := db.SetInt("visits", 100)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetInt(key string, n int64) error {
+	return b.Set(key, encodeInt(n))
+}
+
+// GetInt 读取由 SetInt/IncrInt 存储的整数
+// key不存在时返回 ErrNotFound，存储的数据不是8字节时返回 ErrInvalidInt
+// 示例：
+//
+//	n, err := db.GetInt("visits")
+//	if errors.Is(err, rbadger.ErrNotFound) {
+//	    n = 0
+//	} else if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetInt(key string) (int64, error) {
+	data, err := b.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return decodeInt(key, data)
+}
+
+// IncrInt 将以二进制格式存储的整数增加delta并返回新值，key不存在时从0开始
+// 读取与写入在同一个事务中完成，遇到并发冲突时自动重试。
+// 结果超出int64范围时返回 ErrIntegerOverflow，且不修改原值
+// 示例：
+//
+//	n, err := db.IncrInt("visits", 1)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) IncrInt(key string, delta int64) (int64, error) {
+	defer b.invalidate(key)
+
+	var n int64
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		n = 0
+		item, err := txn.Get([]byte(key))
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				n, err = decodeInt(key, val)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if n, err = addCounter(n, delta, math.MinInt64, math.MaxInt64); err != nil {
+			return err
+		}
+		return txn.Set([]byte(key), encodeInt(n))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// encodeInt 将整数编码为8字节大端序
+func encodeInt(n int64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(n))
+	return buf
+}
+
+// decodeInt 解码8字节大端序的整数
+func decodeInt(key string, data []byte) (int64, error) {
+	if len(data) != 8 {
+		return 0, fmt.Errorf("%w: key %q has %d bytes", ErrInvalidInt, key, len(data))
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
@@ -0,0 +1,61 @@
+package rbadger
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+)
+
+// TestIntHelpers 测试二进制整数的存取与递增
+func TestIntHelpers(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetInt("n", -5); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.GetInt("n"); err != nil || n != -5 {
+		t.Errorf("读取结果不正确: %d, %v", n, err)
+	}
+	if raw, _ := db.Get("n"); len(raw) != 8 {
+		t.Errorf("应存储为8字节: %d", len(raw))
+	}
+
+	if _, err := db.GetInt("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("key不存在时应返回ErrNotFound: %v", err)
+	}
+	db.SetS("bad", "12")
+	if _, err := db.GetInt("bad"); !errors.Is(err, ErrInvalidInt) {
+		t.Errorf("数据无效时应返回ErrInvalidInt: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.IncrInt("counter", 2); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, _ := db.GetInt("counter"); n != 40 {
+		t.Errorf("并发递增结果不正确: %d", n)
+	}
+}
+
+// TestIncrIntOverflow 测试IncrInt溢出时返回错误且不修改原值
+func TestIncrIntOverflow(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetInt("max", math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.IncrInt("max", 1); !errors.Is(err, ErrIntegerOverflow) {
+		t.Errorf("溢出时应返回ErrIntegerOverflow: %v", err)
+	}
+	if n, _ := db.GetInt("max"); n != math.MaxInt64 {
+		t.Errorf("溢出时不应修改原值: %d", n)
+	}
+}
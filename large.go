@@ -0,0 +1,189 @@
+package rbadger
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// largeManifest 记录大值的分块信息
+type largeManifest struct {
+	Chunks int
+	Size   int
+	Gen    uint64 // 分块所属的写入批次，旧数据中为0
+}
+
+// chunkKey 返回大值第n个分块的key
+// 每次写入使用新的批次，分块存储在 key:chunk:<gen>:<n> 下；旧数据的分块存储在 key:chunk:<n> 下
+func (m largeManifest) chunkKey(key string, n int) []byte {
+	if m.Gen == 0 {
+		return []byte(key + ":chunk:" + strconv.Itoa(n))
+	}
+	return []byte(key + ":chunk:" + strconv.FormatUint(m.Gen, 16) + ":" + strconv.Itoa(n))
+}
+
+// deleteLargeChunks 删除清单对应的全部分块
+func (b *BadgerDB) deleteLargeChunks(key string, manifest largeManifest) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for n := 0; n < manifest.Chunks; n++ {
+		if err := wb.Delete(manifest.chunkKey(key, n)); err != nil {
+			return err
+		}
+	}
+	return wb.Flush()
+}
+
+// readLargeManifest 在事务中读取大值的分块信息
+func readLargeManifest(txn *badger.Txn, key string) (largeManifest, error) {
+	var manifest largeManifest
+	item, err := txn.Get([]byte(key))
+	if err != nil {
+		return manifest, err
+	}
+	err = item.Value(func(val []byte) error {
+		return gob.NewDecoder(bytes.NewReader(val)).Decode(&manifest)
+	})
+	return manifest, err
+}
+
+// SetLarge 将较大的值按chunkSize分块存储
+// 各分块存储在以本次写入批次区分的 key:chunk:<gen>:<n> 下，key 本身存储记录批次、分块数量和总大小的清单。
+// 分块全部写入后才在一个事务中替换清单，之后再删除旧批次的分块，
+// 因此并发的 GetLarge 总是读到完整的旧值或新值；写入中途失败时清单不变，只会残留未被引用的新分块
+// 示例：
+//
+//	err := db.SetLarge("doc:1", data, 1<<20) // 每块1MB
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetLarge(key string, value []byte, chunkSize int) error {
+	if chunkSize <= 0 {
+		return errors.New("rbadger: chunk size must be positive")
+	}
+	defer b.purgeReadCache()
+
+	manifest := largeManifest{
+		Chunks: (len(value) + chunkSize - 1) / chunkSize,
+		Size:   len(value),
+	}
+	// 随机生成批次，避免并发写入同一个key时分块互相覆盖
+	for manifest.Gen == 0 {
+		manifest.Gen = rand.Uint64()
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(manifest); err != nil {
+		return err
+	}
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for n := 0; n < manifest.Chunks; n++ {
+		end := (n + 1) * chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		if err := wb.Set(manifest.chunkKey(key, n), value[n*chunkSize:end]); err != nil {
+			return err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return err
+	}
+
+	var old largeManifest
+	var replaced bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var err error
+		old, err = readLargeManifest(txn, key)
+		replaced = err == nil
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		return txn.Set([]byte(key), buf.Bytes())
+	})
+	if err != nil {
+		b.deleteLargeChunks(key, manifest)
+		return err
+	}
+
+	if !replaced {
+		return nil
+	}
+	return b.deleteLargeChunks(key, old)
+}
+
+// GetLarge 读取由 SetLarge 存储的值并重新拼接
+// 示例：
+//
+//	data, err := db.GetLarge("doc:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetLarge(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		manifest, err := readLargeManifest(txn, key)
+		if err != nil {
+			return err
+		}
+
+		value = make([]byte, 0, manifest.Size)
+		for n := 0; n < manifest.Chunks; n++ {
+			item, err := txn.Get(manifest.chunkKey(key, n))
+			if err != nil {
+				return err
+			}
+			err = item.Value(func(val []byte) error {
+				value = append(value, val...)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(value) != manifest.Size {
+			return errors.New("rbadger: large value size mismatch")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return value, nil
+}
+
+// DelLarge 删除由 SetLarge 存储的值及其全部分块
+// 清单在一个事务中读取并删除，提交后再删除该清单引用的分块，并发的 SetLarge 写入的新值不会被误删
+// 示例：
+//
+//	err := db.DelLarge("doc:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DelLarge(key string) error {
+	defer b.purgeReadCache()
+
+	var manifest largeManifest
+	var found bool
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var err error
+		manifest, err = readLargeManifest(txn, key)
+		found = err == nil
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return txn.Delete([]byte(key))
+	})
+	if err != nil || !found {
+		return err
+	}
+	return b.deleteLargeChunks(key, manifest)
+}
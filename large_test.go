@@ -0,0 +1,127 @@
+package rbadger
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestLargeValue 测试大值的分块存储
+func TestLargeValue(t *testing.T) {
+	db := newTestDB(t)
+
+	data := bytes.Repeat([]byte("0123456789"), 1000) // 10000字节
+	if err := db.SetLarge("doc", data, 3000); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := db.FindKeys("doc:chunk:"); len(keys) != 4 {
+		t.Errorf("期望4个分块，实际为%d个", len(keys))
+	}
+
+	got, err := db.GetLarge("doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("重新拼接的数据不一致")
+	}
+
+	// 覆盖为更小的值时清理多余的分块
+	if err := db.SetLarge("doc", data[:5000], 3000); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := db.FindKeys("doc:chunk:"); len(keys) != 2 {
+		t.Errorf("期望2个分块，实际为%d个", len(keys))
+	}
+	if got, _ := db.GetLarge("doc"); !bytes.Equal(got, data[:5000]) {
+		t.Error("覆盖后的数据不一致")
+	}
+
+	if err := db.DelLarge("doc"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := db.FindKeys("doc"); len(keys) != 0 {
+		t.Errorf("删除后不应有残留的key: %v", keys)
+	}
+}
+
+// TestSetLargeConcurrentOverwrite 测试覆盖写入相同大小的值时，并发读取总是得到完整的旧值或新值
+func TestSetLargeConcurrentOverwrite(t *testing.T) {
+	db := newTestDB(t)
+
+	a := bytes.Repeat([]byte("a"), 10000)
+	b := bytes.Repeat([]byte("b"), 10000)
+	if err := db.SetLarge("doc", a, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			next := a
+			if i%2 == 0 {
+				next = b
+			}
+			if err := db.SetLarge("doc", next, 1000); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		got, err := db.GetLarge("doc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, a) && !bytes.Equal(got, b) {
+			t.Fatal("读取到新旧分块混合的数据")
+		}
+	}
+
+	if keys, _ := db.FindKeys("doc:chunk:"); len(keys) != 10 {
+		t.Errorf("旧批次的分块应被删除，实际分块数: %d", len(keys))
+	}
+}
+
+// TestDelLargeConcurrentSet 测试删除与写入并发时不会残留未被引用的分块
+func TestDelLargeConcurrentSet(t *testing.T) {
+	db := newTestDB(t)
+
+	data := bytes.Repeat([]byte("x"), 5000)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if err := db.SetLarge("doc", data, 1000); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for deleting := true; deleting; {
+		select {
+		case <-done:
+			deleting = false
+		default:
+		}
+		if err := db.DelLarge("doc"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, _ := db.FindKeys("doc:chunk:")
+	if db.Exists("doc") {
+		if len(keys) != 5 {
+			t.Errorf("期望只剩当前清单的5个分块，实际为%d", len(keys))
+		}
+	} else if len(keys) != 0 {
+		t.Errorf("清单被删除后不应残留分块，实际为%d", len(keys))
+	}
+}
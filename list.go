@@ -0,0 +1,259 @@
+package rbadger
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// listMeta 记录列表的头尾位置，元素下标范围为 [Head, Tail)
+type listMeta struct {
+	Head int64
+	Tail int64
+}
+
+// len 返回列表长度
+func (m listMeta) len() int64 {
+	return m.Tail - m.Head
+}
+
+// listElemKey 返回列表中指定下标元素的key
+func listElemKey(key string, index int64) []byte {
+	return []byte(key + ":" + strconv.FormatInt(index, 10))
+}
+
+// readListMeta 在事务中读取列表的元数据，列表不存在时返回空列表
+func readListMeta(txn *badger.Txn, key string) (listMeta, error) {
+	item, err := txn.Get([]byte(key))
+	if err == badger.ErrKeyNotFound {
+		return listMeta{}, nil
+	}
+	if err != nil {
+		return listMeta{}, err
+	}
+
+	var meta listMeta
+	err = item.Value(func(val []byte) error {
+		if len(val) != 16 {
+			return errors.New("rbadger: value is not a list")
+		}
+		meta.Head = int64(binary.BigEndian.Uint64(val[:8]))
+		meta.Tail = int64(binary.BigEndian.Uint64(val[8:]))
+		return nil
+	})
+	return meta, err
+}
+
+// writeListMeta 在事务中写入列表的元数据，列表为空时删除元数据
+func writeListMeta(txn *badger.Txn, key string, meta listMeta) error {
+	if meta.len() == 0 {
+		return txn.Delete([]byte(key))
+	}
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(meta.Head))
+	binary.BigEndian.PutUint64(buf[8:], uint64(meta.Tail))
+	return txn.Set([]byte(key), buf)
+}
+
+// listPush 在事务中向列表头部(left)或尾部追加元素，返回追加后的长度
+func listPush(txn *badger.Txn, key string, left bool, values ...[]byte) (int, error) {
+	meta, err := readListMeta(txn, key)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, value := range values {
+		var index int64
+		if left {
+			meta.Head--
+			index = meta.Head
+		} else {
+			index = meta.Tail
+			meta.Tail++
+		}
+		if err := txn.Set(listElemKey(key, index), value); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writeListMeta(txn, key, meta); err != nil {
+		return 0, err
+	}
+	return int(meta.len()), nil
+}
+
+// listPop 在事务中从列表头部(left)或尾部弹出一个元素，列表为空时返回nil
+func listPop(txn *badger.Txn, key string, left bool) ([]byte, error) {
+	meta, err := readListMeta(txn, key)
+	if err != nil || meta.len() == 0 {
+		return nil, err
+	}
+
+	var index int64
+	if left {
+		index = meta.Head
+		meta.Head++
+	} else {
+		meta.Tail--
+		index = meta.Tail
+	}
+
+	elemKey := listElemKey(key, index)
+	item, err := txn.Get(elemKey)
+	if err != nil {
+		return nil, err
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := txn.Delete(elemKey); err != nil {
+		return nil, err
+	}
+	if err := writeListMeta(txn, key, meta); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// LPush 将一个或多个值依次插入列表头部，返回插入后的列表长度
+// 列表以key存储头尾位置，元素存储为 key:<下标>；所有修改在一个事务中完成，
+// 遇到并发冲突时自动重试，因此并发写入不会破坏头尾位置
+// 示例：
+//
+//	n, err := db.LPush("jobs", []byte("a"), []byte("b"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	// 列表内容为 b, a
+func (b *BadgerDB) LPush(key string, values ...[]byte) (int, error) {
+	return b.push(key, true, values)
+}
+
+// RPush 将一个或多个值依次追加到列表尾部，返回追加后的列表长度
+// 示例：
+//
+//	n, err := db.RPush("jobs", []byte("a"), []byte("b"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	// 列表内容为 a, b
+func (b *BadgerDB) RPush(key string, values ...[]byte) (int, error) {
+	return b.push(key, false, values)
+}
+
+// LPop 移除并返回列表的第一个元素，列表为空或不存在时返回nil
+// 示例：
+//
+//	value, err := db.LPop("jobs")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("列表为空")
+//	}
+func (b *BadgerDB) LPop(key string) ([]byte, error) {
+	return b.pop(key, true)
+}
+
+// RPop 移除并返回列表的最后一个元素，列表为空或不存在时返回nil
+// 示例：
+//
+//	value, err := db.RPop("jobs")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) RPop(key string) ([]byte, error) {
+	return b.pop(key, false)
+}
+
+// LRange 返回列表中下标在[start, stop]范围内的元素
+// 与Redis相同，下标从0开始，负数表示从尾部倒数（-1为最后一个元素），超出范围的下标会被截断
+// 示例：
+//
+//	all, err := db.LRange("jobs", 0, -1)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) LRange(key string, start, stop int) ([][]byte, error) {
+	var result [][]byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		meta, err := readListMeta(txn, key)
+		if err != nil {
+			return err
+		}
+
+		n := int(meta.len())
+		if start < 0 {
+			start += n
+		}
+		if stop < 0 {
+			stop += n
+		}
+		if start < 0 {
+			start = 0
+		}
+		if stop >= n {
+			stop = n - 1
+		}
+
+		for i := start; i <= stop; i++ {
+			item, err := txn.Get(listElemKey(key, meta.Head+int64(i)))
+			if err != nil {
+				return err
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			result = append(result, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// push 在一个事务中向列表头部(left)或尾部追加元素
+func (b *BadgerDB) push(key string, left bool, values [][]byte) (int, error) {
+	for _, value := range values {
+		if err := b.cfg.checkValueLen(value); err != nil {
+			return 0, err
+		}
+	}
+	defer b.invalidate(key)
+
+	var n int
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var err error
+		n, err = listPush(txn, key, left, values...)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// pop 在一个事务中从列表头部(left)或尾部弹出一个元素
+func (b *BadgerDB) pop(key string, left bool) ([]byte, error) {
+	defer b.invalidate(key)
+
+	var value []byte
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var err error
+		value, err = listPop(txn, key, left)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		b.noteDeletes(1)
+	}
+	return value, nil
+}
@@ -0,0 +1,81 @@
+package rbadger
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestList 测试列表的插入、弹出与范围读取
+func TestList(t *testing.T) {
+	db := newTestDB(t)
+
+	if n, err := db.RPush("l", []byte("b"), []byte("c")); err != nil || n != 2 {
+		t.Fatalf("RPush结果不正确: %d, %v", n, err)
+	}
+	if n, err := db.LPush("l", []byte("a")); err != nil || n != 3 {
+		t.Fatalf("LPush结果不正确: %d, %v", n, err)
+	}
+
+	cases := []struct {
+		start, stop int
+		want        string
+	}{
+		{0, -1, "abc"},
+		{1, 1, "b"},
+		{-2, 10, "bc"},
+		{-10, 0, "a"},
+		{2, 1, ""},
+	}
+	for _, c := range cases {
+		values, err := db.LRange("l", c.start, c.stop)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		for _, v := range values {
+			got += string(v)
+		}
+		if got != c.want {
+			t.Errorf("LRange(%d, %d) = %q, 期望 %q", c.start, c.stop, got, c.want)
+		}
+	}
+
+	if v, _ := db.LPop("l"); string(v) != "a" {
+		t.Errorf("LPop结果不正确: %s", v)
+	}
+	if v, _ := db.RPop("l"); string(v) != "c" {
+		t.Errorf("RPop结果不正确: %s", v)
+	}
+	db.LPop("l")
+	if v, err := db.RPop("l"); err != nil || v != nil {
+		t.Errorf("空列表应返回nil: %v, %v", v, err)
+	}
+}
+
+// TestListConcurrentPush 测试并发插入不会破坏头尾位置
+func TestListConcurrentPush(t *testing.T) {
+	db := newTestDB(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				_, err = db.LPush("l", []byte("x"))
+			} else {
+				_, err = db.RPush("l", []byte("x"))
+			}
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	values, err := db.LRange("l", 0, -1)
+	if err != nil || len(values) != 20 {
+		t.Errorf("并发插入后长度不正确: %d, %v", len(values), err)
+	}
+}
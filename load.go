@@ -0,0 +1,92 @@
+package rbadger
+
+import (
+	"errors"
+	"time"
+)
+
+// GetOrSet 获取key的值，key不存在时调用loader生成并写入后返回
+// key存在时不会调用loader；同一key同时只会有一个loader在执行，并发的其他调用等待并共享其结果。
+// loader返回错误时不写入，所有等待的调用都会收到该错误
+// 示例：
+//
+//	val, err := db.GetOrSet("config:default", func() ([]byte, error) {
+//	    return buildDefaultConfig()
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetOrSet(key string, loader func() ([]byte, error)) ([]byte, error) {
+	val, err := b.Get(key)
+	if !errors.Is(err, ErrNotFound) {
+		return val, err
+	}
+
+	v, err, _ := b.loads.Do("plain:"+key, func() (interface{}, error) {
+		// 等待期间可能已被其他加载写入
+		val, err := b.Get(key)
+		if !errors.Is(err, ErrNotFound) {
+			return val, err
+		}
+
+		val, err = loader()
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			val = []byte{}
+		}
+		if err := b.Set(key, val); err != nil {
+			return nil, err
+		}
+		return val, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 各调用方获得独立的副本
+	return append([]byte{}, v.([]byte)...), nil
+}
+
+// XGetOrSet 获取带过期时间的缓存数据，不存在或已过期时调用loader加载并通过 XSetEx 写入
+// 数据存在时不会调用loader；同一key同时只会有一个loader在执行，并发的其他调用等待并共享其结果，
+// 避免缓存击穿。loader返回错误时不写入缓存，所有等待的调用都会收到该错误
+// 示例：
+//
+//	val, err := db.XGetOrSet("user:1", 10*time.Minute, func() ([]byte, error) {
+//	    return loadUserFromDB(1)
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) XGetOrSet(key string, ttl time.Duration, loader func() ([]byte, error)) ([]byte, error) {
+	val, err := b.XGet(key)
+	if err != nil || val != nil {
+		return val, err
+	}
+
+	v, err, _ := b.loads.Do("cache:"+key, func() (interface{}, error) {
+		// 等待期间可能已被其他加载写入
+		val, err := b.XGet(key)
+		if err != nil || val != nil {
+			return val, err
+		}
+
+		val, err = loader()
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			val = []byte{}
+		}
+		if err := b.XSetEx(key, val, ttl); err != nil {
+			return nil, err
+		}
+		return val, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 各调用方获得独立的副本
+	return append([]byte{}, v.([]byte)...), nil
+}
@@ -0,0 +1,136 @@
+package rbadger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestXGetOrSetConcurrent 测试并发未命中时只调用一次loader
+func TestXGetOrSetConcurrent(t *testing.T) {
+	db := newTestDB(t)
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte("loaded"), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]byte, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val, err := db.XGetOrSet("k", time.Minute, loader)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = val
+		}(i)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("loader应只调用一次，实际%d次", n)
+	}
+	for _, val := range results {
+		if string(val) != "loaded" {
+			t.Errorf("结果不正确: %q", val)
+		}
+	}
+
+	// 已写入缓存，不再调用loader
+	val, err := db.XGetOrSet("k", time.Minute, func() ([]byte, error) {
+		t.Error("命中缓存时不应调用loader")
+		return nil, nil
+	})
+	if err != nil || string(val) != "loaded" {
+		t.Errorf("获取失败: %q, %v", val, err)
+	}
+}
+
+// TestXGetOrSetError 测试loader返回错误时不写入缓存
+func TestXGetOrSetError(t *testing.T) {
+	db := newTestDB(t)
+
+	errLoad := errors.New("load failed")
+	if _, err := db.XGetOrSet("k", time.Minute, func() ([]byte, error) {
+		return nil, errLoad
+	}); !errors.Is(err, errLoad) {
+		t.Errorf("应返回loader的错误: %v", err)
+	}
+	if val, _ := db.XGet("k"); val != nil {
+		t.Error("loader失败时不应写入缓存")
+	}
+}
+
+// TestGetOrSet 测试GetOrSet和XGetOrSet方法
+func TestGetOrSet(t *testing.T) {
+	db := newTestDB(t)
+
+	db.SetS("exists", "v")
+	val, err := db.GetOrSet("exists", func() ([]byte, error) {
+		t.Error("key存在时不应调用loader")
+		return nil, nil
+	})
+	if err != nil || string(val) != "v" {
+		t.Errorf("应返回已有的值: %q, %v", val, err)
+	}
+
+	val, err = db.GetOrSet("missing", func() ([]byte, error) {
+		return []byte("loaded"), nil
+	})
+	if err != nil || string(val) != "loaded" {
+		t.Errorf("应返回loader的结果: %q, %v", val, err)
+	}
+	if v, _ := db.GetS("missing"); v != "loaded" {
+		t.Errorf("loader的结果应被写入: %q", v)
+	}
+
+	val, err = db.XGetOrSet("cache", time.Hour, func() ([]byte, error) {
+		return []byte("x"), nil
+	})
+	if err != nil || string(val) != "x" {
+		t.Errorf("应返回loader的结果: %q, %v", val, err)
+	}
+	if ttl, _ := db.XTTL("cache"); ttl <= 0 {
+		t.Errorf("应设置过期时间: %d", ttl)
+	}
+}
+
+// TestXGetOrSetPerKey 测试不同key的加载互不阻塞
+func TestXGetOrSetPerKey(t *testing.T) {
+	db := newTestDB(t)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		db.XGetOrSet("slow", time.Minute, func() ([]byte, error) {
+			<-release
+			return []byte("slow"), nil
+		})
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// slow 的加载尚未完成，其他key仍可立即加载
+	val, err := db.XGetOrSet("fast", time.Minute, func() ([]byte, error) {
+		return []byte("fast"), nil
+	})
+	if err != nil || string(val) != "fast" {
+		t.Errorf("其他key的加载结果不正确: %q, %v", val, err)
+	}
+
+	close(release)
+	<-done
+	if v, _ := db.XGetS("slow"); v != "slow" {
+		t.Errorf("slow的加载结果不正确: %q", v)
+	}
+}
@@ -0,0 +1,197 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// Manager 统一管理多个按名称区分的 BadgerDB 实例
+// 提供共享的垃圾回收/备份调度，以及统一关闭
+type Manager struct {
+	mu      sync.RWMutex
+	dbs     map[string]*BadgerDB
+	onError func(name string, err error)
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager 创建一个新的 Manager 实例
+// 示例：
+//
+//	m := NewManager()
+//	defer m.Close()
+//	users, err := m.Open("users", "./data/users")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func NewManager() *Manager {
+	return &Manager{dbs: make(map[string]*BadgerDB)}
+}
+
+// Open 打开指定路径的数据库并以name注册到 Manager 中
+func (m *Manager) Open(name, dbPath string) (*BadgerDB, error) {
+	return m.OpenWithOptions(name, badger.DefaultOptions(dbPath))
+}
+
+// OpenWithOptions 使用自定义选项打开数据库并以name注册到 Manager 中
+func (m *Manager) OpenWithOptions(name string, opts badger.Options) (*BadgerDB, error) {
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Add(name, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Add 将已打开的数据库以name注册到 Manager 中，之后由 Manager 负责关闭
+func (m *Manager) Add(name string, db *BadgerDB) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.dbs[name]; ok {
+		return fmt.Errorf("rbadger: database %q already registered", name)
+	}
+	m.dbs[name] = db
+	return nil
+}
+
+// Get 获取指定名称的数据库，不存在时返回nil
+func (m *Manager) Get(name string) *BadgerDB {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.dbs[name]
+}
+
+// Names 返回所有已注册数据库的名称（按字典序）
+func (m *Manager) Names() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.dbs))
+	for name := range m.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OnError 设置调度任务出错时的回调
+func (m *Manager) OnError(fn func(name string, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = fn
+}
+
+// StartScheduler 启动共享的维护调度，每隔interval依次对所有数据库运行垃圾回收，
+// 当backupDir不为空时，同时将每个数据库完整备份到 backupDir/<name>.bak
+// 重复调用时保持已运行的调度不变
+// 示例：
+//
+//	m.StartScheduler(10*time.Minute, 0.5, "./backup")
+func (m *Manager) StartScheduler(interval time.Duration, discardRatio float64, backupDir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	m.stop = stop
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				m.runMaintenance(discardRatio, backupDir)
+			}
+		}
+	}()
+}
+
+// StopScheduler 停止共享的维护调度并等待正在进行的任务结束
+func (m *Manager) StopScheduler() {
+	m.mu.Lock()
+	stop := m.stop
+	m.stop = nil
+	m.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+	}
+	m.wg.Wait()
+}
+
+// runMaintenance 对所有数据库执行一轮垃圾回收和备份
+func (m *Manager) runMaintenance(discardRatio float64, backupDir string) {
+	m.mu.RLock()
+	dbs := make(map[string]*BadgerDB, len(m.dbs))
+	for name, db := range m.dbs {
+		dbs[name] = db
+	}
+	onError := m.onError
+	m.mu.RUnlock()
+
+	for name, db := range dbs {
+		err := db.runGCCycle(discardRatio)
+		if err == nil && backupDir != "" {
+			err = backupToFile(db, filepath.Join(backupDir, name+".bak"))
+		}
+		if err != nil && onError != nil {
+			onError(name, err)
+		}
+	}
+}
+
+// backupToFile 将数据库完整备份到指定文件，先写入临时文件再重命名
+func backupToFile(db *BadgerDB, path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Backup(f, 0); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Close 停止维护调度并关闭所有已注册的数据库
+func (m *Manager) Close() error {
+	m.StopScheduler()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for name, db := range m.dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("rbadger: close %q: %w", name, err))
+		}
+	}
+	m.dbs = make(map[string]*BadgerDB)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package rbadger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestManager 测试Manager的注册、调度与关闭
+func TestManager(t *testing.T) {
+	dir := "./test_manager_db"
+	defer os.RemoveAll(dir)
+
+	m := NewManager()
+	users, err := m.Open("users", filepath.Join(dir, "users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Open("orders", filepath.Join(dir, "orders")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Open("users", filepath.Join(dir, "dup")); err == nil {
+		t.Error("重复注册应该返回错误")
+	}
+
+	if m.Get("users") != users {
+		t.Error("Get返回的实例不正确")
+	}
+	if m.Get("missing") != nil {
+		t.Error("未注册的名称应返回nil")
+	}
+	if err := users.SetS("u:1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	backupDir := filepath.Join(dir, "backup")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	m.StartScheduler(100*time.Millisecond, 0.5, backupDir)
+	time.Sleep(300 * time.Millisecond)
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"users", "orders"} {
+		if _, err := os.Stat(filepath.Join(backupDir, name+".bak")); err != nil {
+			t.Errorf("%s的备份文件不存在: %v", name, err)
+		}
+	}
+	if len(m.Names()) != 0 {
+		t.Error("关闭后不应再有已注册的数据库")
+	}
+}
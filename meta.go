@@ -0,0 +1,77 @@
+package rbadger
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+const (
+	// reservedPrefix 包内部使用的保留key前缀，不会出现在普通扫描结果中
+	reservedPrefix = "__rbadger:"
+	// metaPrefix 元数据存储使用的key前缀
+	metaPrefix = reservedPrefix + "meta:"
+	// schemaVersionKey 应用schema版本号对应的元数据key
+	schemaVersionKey = "schema_version"
+)
+
+// isReservedKey 判断key是否属于包内部保留的key
+func isReservedKey(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(reservedPrefix))
+}
+
+// SetMeta 设置元数据
+// 元数据存储在保留前缀下，不会出现在 FindKeys 等普通扫描结果中
+// 示例：
+//
+//	err := db.SetMeta("created_by", []byte("v1.2.0"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetMeta(key string, value []byte) error {
+	return b.Set(metaPrefix+key, value)
+}
+
+// GetMeta 获取元数据
+// 示例：
+//
+//	value, err := db.GetMeta("created_by")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetMeta(key string) ([]byte, error) {
+	return b.Get(metaPrefix + key)
+}
+
+// SchemaVersion 获取应用的schema版本号，未设置时返回0
+// 示例：
+//
+//	version, err := db.SchemaVersion()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if version < 2 {
+//	    migrateToV2(db)
+//	    db.SetSchemaVersion(2)
+//	}
+func (b *BadgerDB) SchemaVersion() (int, error) {
+	value, err := b.GetMeta(schemaVersionKey)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(value))
+}
+
+// SetSchemaVersion 设置应用的schema版本号
+// 示例：
+//
+//	err := db.SetSchemaVersion(2)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetSchemaVersion(version int) error {
+	return b.SetMeta(schemaVersionKey, []byte(strconv.Itoa(version)))
+}
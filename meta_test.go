@@ -0,0 +1,40 @@
+package rbadger
+
+import (
+	"testing"
+)
+
+// TestMeta 测试元数据存储
+func TestMeta(t *testing.T) {
+	db := newTestDB(t)
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Errorf("未设置时schema版本应为0，实际为%d", version)
+	}
+
+	if err := db.SetSchemaVersion(3); err != nil {
+		t.Fatal(err)
+	}
+	if version, _ = db.SchemaVersion(); version != 3 {
+		t.Errorf("schema版本不正确: %d", version)
+	}
+
+	if err := db.SetMeta("owner", []byte("svc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetS("user:1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := db.FindKeys("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "user:1" {
+		t.Errorf("元数据不应出现在扫描结果中: %v", keys)
+	}
+}
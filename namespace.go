@@ -0,0 +1,282 @@
+package rbadger
+
+import (
+	"strings"
+	"time"
+)
+
+// Namespaced 数据库中某个前缀下的隔离视图
+// 所有key都会自动加上前缀，返回的key会去掉前缀，扫描只能看到前缀下的数据，
+// 适合多个组件共享同一个数据库时，为每个组件提供互不干扰的命名空间
+type Namespaced struct {
+	db     *BadgerDB
+	prefix string
+}
+
+// Namespace 返回以prefix为命名空间的视图，视图与原数据库共享底层存储
+// 示例：
+//
+//	users := db.Namespace("users:")
+//	users.SetS("1", "Tom")  // 实际写入的key为 users:1
+//	keys, _ := users.FindKeys("") // 返回 ["1"]
+func (b *BadgerDB) Namespace(prefix string) *Namespaced {
+	return &Namespaced{db: b, prefix: prefix}
+}
+
+// Namespace 返回当前命名空间下的子命名空间，前缀为两者拼接
+// 示例：
+//
+//	sessions := db.Namespace("app:").Namespace("session:") // 前缀为 app:session:
+func (n *Namespaced) Namespace(prefix string) *Namespaced {
+	return &Namespaced{db: n.db, prefix: n.prefix + prefix}
+}
+
+// Prefix 返回命名空间的完整前缀
+// 示例：
+//
+//	prefix := db.Namespace("app:").Namespace("session:").Prefix() // "app:session:"
+func (n *Namespaced) Prefix() string {
+	return n.prefix
+}
+
+// DB 返回命名空间所属的数据库
+// 示例：
+//
+//	users := db.Namespace("users:")
+//	users.DB().RunGC(0.5) // 对整个数据库执行操作
+func (n *Namespaced) DB() *BadgerDB {
+	return n.db
+}
+
+// key 返回加上前缀后的完整key
+func (n *Namespaced) key(key string) string {
+	return n.prefix + key
+}
+
+// strip 去掉keys中的前缀
+func (n *Namespaced) strip(keys []string) []string {
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, n.prefix)
+	}
+	return keys
+}
+
+// Get 获取命名空间中key的值，与 BadgerDB.Get 相同
+// 示例：
+//
+//	users := db.Namespace("users:")
+//	value, err := users.Get("1") // 读取 users:1
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) Get(key string) ([]byte, error) {
+	return n.db.Get(n.key(key))
+}
+
+// GetS 获取命名空间中key的字符串值
+// 示例：
+//
+//	name, err := users.GetS("1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) GetS(key string) (string, error) {
+	return n.db.GetS(n.key(key))
+}
+
+// Set 设置命名空间中key的值
+// 示例：
+//
+//	err := users.Set("1", []byte("Tom")) // 写入 users:1
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) Set(key string, value []byte) error {
+	return n.db.Set(n.key(key), value)
+}
+
+// SetS 设置命名空间中key的字符串值
+// 示例：
+//
+//	err := users.SetS("1", "Tom")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) SetS(key string, value string) error {
+	return n.db.SetS(n.key(key), value)
+}
+
+// Del 删除命名空间中的key
+// 示例：
+//
+//	err := users.Del("1") // 删除 users:1
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) Del(key string) error {
+	return n.db.Del(n.key(key))
+}
+
+// Exists 检查命名空间中的key是否存在
+// 示例：
+//
+//	if users.Exists("1") {
+//	    fmt.Println("用户存在")
+//	}
+func (n *Namespaced) Exists(key string) bool {
+	return n.db.Exists(n.key(key))
+}
+
+// XGet 获取命名空间中带过期时间的缓存数据，与 BadgerDB.XGet 相同
+// 示例：
+//
+//	sessions := db.Namespace("session:")
+//	value, err := sessions.XGet("abc")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("会话不存在或已过期")
+//	}
+func (n *Namespaced) XGet(key string) ([]byte, error) {
+	return n.db.XGet(n.key(key))
+}
+
+// XGetS 获取命名空间中带过期时间的字符串数据
+// 示例：
+//
+//	value, err := sessions.XGetS("abc")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) XGetS(key string) (string, error) {
+	return n.db.XGetS(n.key(key))
+}
+
+// XSet 在命名空间中存储永不过期的缓存数据
+// 示例：
+//
+//	err := sessions.XSet("abc", data)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) XSet(key string, value []byte) error {
+	return n.db.XSet(n.key(key), value)
+}
+
+// XSetEx 在命名空间中存储带过期时间的缓存数据
+// 示例：
+//
+//	err := sessions.XSetEx("abc", data, 30*time.Minute) // 写入 session:abc
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) XSetEx(key string, value []byte, expires time.Duration) error {
+	return n.db.XSetEx(n.key(key), value, expires)
+}
+
+// XTTL 获取命名空间中key的剩余生存时间，与 BadgerDB.XTTL 相同
+// 示例：
+//
+//	ttl, err := sessions.XTTL("abc")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) XTTL(key string) (int64, error) {
+	return n.db.XTTL(n.key(key))
+}
+
+// XExists 检查命名空间中带过期时间存储的key是否存在且未过期
+// 示例：
+//
+//	if sessions.XExists("abc") {
+//	    fmt.Println("会话有效")
+//	}
+func (n *Namespaced) XExists(key string) bool {
+	return n.db.XExists(n.key(key))
+}
+
+// FindKeys 返回命名空间中匹配指定前缀的key，返回的key不包含命名空间前缀
+// 示例：
+//
+//	keys, err := users.FindKeys("") // 返回 ["1", "2"]，而不是 ["users:1", "users:2"]
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) FindKeys(prefix string) ([]string, error) {
+	keys, err := n.db.FindKeys(n.key(prefix))
+	return n.strip(keys), err
+}
+
+// FindXKeys 返回命名空间中匹配指定前缀且未过期的缓存key，返回的key不包含命名空间前缀
+// 示例：
+//
+//	keys, err := sessions.FindXKeys("")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) FindXKeys(prefix string) ([]string, error) {
+	keys, err := n.db.FindXKeys(n.key(prefix))
+	return n.strip(keys), err
+}
+
+// CountKeys 统计命名空间中匹配指定前缀的key数量
+// 示例：
+//
+//	n, err := users.CountKeys("")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) CountKeys(prefix string) (int64, error) {
+	return n.db.CountKeys(n.key(prefix))
+}
+
+// ScanKeys 分页扫描命名空间中匹配指定前缀的key，cursor与返回的nextCursor均不包含命名空间前缀
+// 示例：
+//
+//	cursor := ""
+//	for {
+//	    keys, next, err := users.ScanKeys("", cursor, 100)
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    process(keys)
+//	    if next == "" {
+//	        break
+//	    }
+//	    cursor = next
+//	}
+func (n *Namespaced) ScanKeys(prefix string, cursor string, limit int) ([]string, string, error) {
+	if cursor != "" {
+		cursor = n.key(cursor)
+	}
+	keys, next, err := n.db.ScanKeys(n.key(prefix), cursor, limit)
+	if next != "" {
+		next = strings.TrimPrefix(next, n.prefix)
+	}
+	return n.strip(keys), next, err
+}
+
+// ForEach 依次对命名空间中匹配指定前缀的每个key调用fn，fn收到的key不包含命名空间前缀
+// 示例：
+//
+//	err := users.ForEach("", func(key string, value []byte) error {
+//	    fmt.Printf("%s: %s\n", key, value) // key不包含 users: 前缀
+//	    return nil
+//	})
+func (n *Namespaced) ForEach(prefix string, fn func(key string, value []byte) error) error {
+	return n.db.ForEach(n.key(prefix), func(key string, value []byte) error {
+		return fn(strings.TrimPrefix(key, n.prefix), value)
+	})
+}
+
+// DelPrefix 删除命名空间中匹配指定前缀的所有key，与 BadgerDB.DelPrefix 相同
+// 示例：
+//
+//	err := users.DelPrefix("") // 删除 users: 下的所有key
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (n *Namespaced) DelPrefix(prefix string) error {
+	return n.db.DelPrefix(n.key(prefix))
+}
@@ -0,0 +1,66 @@
+package rbadger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestNamespace 测试命名空间视图的读写与扫描隔离
+func TestNamespace(t *testing.T) {
+	db := newTestDB(t)
+	users := db.Namespace("users:")
+	orders := db.Namespace("orders:")
+
+	users.SetS("1", "Tom")
+	users.SetS("2", "Jerry")
+	orders.SetS("1", "order")
+	users.XSetEx("session", []byte("s"), time.Hour)
+
+	if v, _ := db.GetS("users:1"); v != "Tom" {
+		t.Errorf("底层key应带有命名空间前缀: %s", v)
+	}
+	if v, _ := users.GetS("1"); v != "Tom" {
+		t.Errorf("命名空间读取结果不正确: %s", v)
+	}
+	if v, _ := users.XGetS("session"); v != "s" {
+		t.Errorf("命名空间XGet结果不正确: %s", v)
+	}
+
+	keys, err := users.FindKeys("")
+	if err != nil || !reflect.DeepEqual(keys, []string{"1", "2", "session"}) {
+		t.Errorf("FindKeys应只返回命名空间内去掉前缀的key: %v, %v", keys, err)
+	}
+	if n, _ := orders.CountKeys(""); n != 1 {
+		t.Errorf("CountKeys结果不正确: %d", n)
+	}
+
+	page, next, err := users.ScanKeys("", "", 2)
+	if err != nil || !reflect.DeepEqual(page, []string{"1", "2"}) || next != "2" {
+		t.Fatalf("ScanKeys第一页不正确: %v, %q, %v", page, next, err)
+	}
+	page, next, _ = users.ScanKeys("", next, 2)
+	if !reflect.DeepEqual(page, []string{"session"}) || next != "" {
+		t.Errorf("ScanKeys第二页不正确: %v, %q", page, next)
+	}
+
+	var seen []string
+	users.ForEach("", func(key string, value []byte) error {
+		seen = append(seen, key)
+		return nil
+	})
+	if len(seen) != 3 || seen[0] != "1" {
+		t.Errorf("ForEach回调的key不正确: %v", seen)
+	}
+
+	nested := db.Namespace("app:").Namespace("cfg:")
+	nested.SetS("port", "80")
+	if nested.Prefix() != "app:cfg:" || !db.Exists("app:cfg:port") {
+		t.Errorf("嵌套命名空间前缀不正确: %s", nested.Prefix())
+	}
+
+	users.Del("1")
+	if users.Exists("1") || !orders.Exists("1") {
+		t.Error("删除只应影响命名空间内的key")
+	}
+}
@@ -0,0 +1,110 @@
+package rbadger
+
+import "fmt"
+
+// config 保存封装层的可选配置
+type config struct {
+	maxResultBytes int  // FindKeys 结果中key的总字节数上限，0表示不限制
+	numCompactors  int  // 覆盖 badger 的 NumCompactors，0表示使用 badger 选项中的值
+	maxValueLen    int  // Set/XSet 等写入值的最大长度，0表示不限制
+	deleteCorrupt  bool // XGet/XTTL 遇到无法解码的数据时是否删除该key
+
+	deleteCompactionThreshold int // 累计删除多少个key后触发一次压缩，0表示不触发
+
+	codec Codec // CacheType 的编解码器，默认为 GobCodec
+}
+
+// Option 用于在创建 BadgerDB 时设置封装层的可选配置
+type Option func(*config)
+
+// WithMaxResultBytes 设置 FindKeys 返回结果中key的总字节数上限
+// 超过上限时返回 ErrResultTooLarge，用于防止前缀过宽导致内存占用过大。默认不限制
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithMaxResultBytes(1<<20))
+func WithMaxResultBytes(n int) Option {
+	return func(c *config) {
+		c.maxResultBytes = n
+	}
+}
+
+// WithNumCompactors 设置 badger 并发压缩的工作协程数量，最小为2
+// 在共享主机上适当调低可以减少后台压缩对前台读写的影响
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithNumCompactors(2))
+func WithNumCompactors(n int) Option {
+	return func(c *config) {
+		if n < 2 {
+			n = 2
+		}
+		c.numCompactors = n
+	}
+}
+
+// WithMaxValueLen 设置写入值的最大字节数，对 Set/XSet 系列、批量写入、原子操作、
+// SetExIndexed、SetStruct 等所有写入用户数据的方法生效；X 系列方法限制的是原始值而非编码后的数据。
+// SetLarge 用于分块存储大值，不受该限制。
+// 超过上限时返回 ErrValueTooLong 且不写入，用于拒绝来自外部的超大数据。默认不限制
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithMaxValueLen(1<<20))
+func WithMaxValueLen(n int) Option {
+	return func(c *config) {
+		c.maxValueLen = n
+	}
+}
+
+// WithDeleteCorrupt 设置 XGet/XTTL 遇到无法解码的数据时自动删除该key
+// 本次读取仍返回 ErrCorruptCacheEntry 以便发现问题，之后的读取视为key不存在。默认不删除
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithDeleteCorrupt())
+func WithDeleteCorrupt() Option {
+	return func(c *config) {
+		c.deleteCorrupt = true
+	}
+}
+
+// WithDeleteCompactionThreshold 设置累计删除n个key后在后台触发一次 badger 的 Flatten 压缩
+// 适用于写入与删除同样频繁的场景（如队列），及时清理删除标记以保持读取延迟稳定。
+// 压缩会将所有层级合并，期间暂停常规的后台压缩并产生大量磁盘I/O，数据量大时可能耗时较长，
+// 因此阈值不宜过小；同一时间只会有一次压缩在进行。默认不触发
+// 示例：
+//
+//	db, err := NewBadgerDB("./data", WithDeleteCompactionThreshold(100000))
+func WithDeleteCompactionThreshold(n int) Option {
+	return func(c *config) {
+		c.deleteCompactionThreshold = n
+	}
+}
+
+// checkValueLen 检查值的长度是否超过 WithMaxValueLen 设置的上限
+func (c config) checkValueLen(value []byte) error {
+	if c.maxValueLen > 0 && len(value) > c.maxValueLen {
+		return fmt.Errorf("%w: %d > %d", ErrValueTooLong, len(value), c.maxValueLen)
+	}
+	return nil
+}
+
+// checkValueLens 检查kvs中的每个值是否超过 WithMaxValueLen 设置的上限
+func (c config) checkValueLens(kvs map[string][]byte) error {
+	for key, value := range kvs {
+		if err := c.checkValueLen(value); err != nil {
+			return fmt.Errorf("key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// newConfig 根据传入的选项生成配置
+func newConfig(options []Option) config {
+	var c config
+	for _, opt := range options {
+		opt(&c)
+	}
+	if c.codec == nil {
+		c.codec = GobCodec{}
+	}
+	return c
+}
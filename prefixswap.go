@@ -0,0 +1,222 @@
+package rbadger
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	// prefixPointerPrefix 版本化前缀当前生效版本的指针key前缀
+	prefixPointerPrefix = reservedPrefix + "pfxptr:"
+	// prefixDataPrefix 版本化前缀各版本数据的key前缀
+	prefixDataPrefix = reservedPrefix + "pfx:"
+	// prefixDropWorker 删除旧版本数据的后台任务名称前缀
+	prefixDropWorker = "prefixdrop:"
+)
+
+// errPrefixVersioned 前缀已切换为版本化存储，需要通过版本切换替换
+var errPrefixVersioned = errors.New("rbadger: prefix is versioned")
+
+// prefixPointerKey 返回前缀当前生效版本的指针key
+func prefixPointerKey(prefix string) []byte {
+	return []byte(prefixPointerPrefix + prefix)
+}
+
+// prefixVersionPrefix 返回某个版本的全部数据所在的key前缀
+func prefixVersionPrefix(gen uint64) []byte {
+	return []byte(prefixDataPrefix + strconv.FormatUint(gen, 16) + ":")
+}
+
+// prefixVersionKey 返回key在某个版本中的存储位置
+func prefixVersionKey(gen uint64, key []byte) []byte {
+	return append(prefixVersionPrefix(gen), key...)
+}
+
+// readPrefixPointer 读取前缀当前生效的版本，前缀未切换为版本化存储时返回0
+func readPrefixPointer(txn *badger.Txn, prefix string) (uint64, error) {
+	item, err := txn.Get(prefixPointerKey(prefix))
+	if err == badger.ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var gen uint64
+	err = item.Value(func(val []byte) error {
+		gen, err = strconv.ParseUint(string(val), 16, 64)
+		return err
+	})
+	return gen, err
+}
+
+// loadVersionedPrefixes 读取所有已切换为版本化存储的前缀，供读取时判断是否需要经指针解析
+func (b *BadgerDB) loadVersionedPrefixes() error {
+	var prefixes []string
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefixPointerPrefix)
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			prefixes = append(prefixes, strings.TrimPrefix(string(it.Item().Key()), prefixPointerPrefix))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(prefixes) > 0 {
+		b.versioned.Store(&prefixes)
+	}
+	return nil
+}
+
+// versionedPrefixOf 返回包含key的最长的版本化前缀，没有时返回false
+func (b *BadgerDB) versionedPrefixOf(key []byte) (string, bool) {
+	prefixes := b.versioned.Load()
+	if prefixes == nil || isReservedKey(key) {
+		return "", false
+	}
+
+	var longest string
+	var found bool
+	for _, prefix := range *prefixes {
+		if bytes.HasPrefix(key, []byte(prefix)) && (!found || len(prefix) > len(longest)) {
+			longest, found = prefix, true
+		}
+	}
+	return longest, found
+}
+
+// resolveKey 返回key在事务中实际存储的位置
+// 属于版本化前缀的key经指针解析到当前生效版本，指针与数据在同一事务中读取，因此总是读到同一个版本
+func (b *BadgerDB) resolveKey(txn *badger.Txn, key []byte) ([]byte, error) {
+	prefix, ok := b.versionedPrefixOf(key)
+	if !ok {
+		return key, nil
+	}
+	gen, err := readPrefixPointer(txn, prefix)
+	if err != nil || gen == 0 {
+		return key, err
+	}
+	return prefixVersionKey(gen, key), nil
+}
+
+// replacePrefixVersioned 通过版本切换整体替换前缀下的内容
+// 新数据先写入新版本，再在一个小事务中切换指针，最后在后台删除旧版本的数据
+func (b *BadgerDB) replacePrefixVersioned(prefix string, kvs map[string][]byte) error {
+	if strings.HasPrefix(reservedPrefix, prefix) {
+		return errors.New("rbadger: prefix covers reserved keys")
+	}
+
+	// 同一时间只进行一次版本切换，保证内存中的前缀列表与指针一致
+	b.versionedMu.Lock()
+	defer b.versionedMu.Unlock()
+
+	var gen uint64
+	for gen == 0 {
+		gen = rand.Uint64()
+	}
+
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+	for key, value := range kvs {
+		if err := wb.Set(prefixVersionKey(gen, []byte(key)), value); err != nil {
+			return err
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		b.dropPrefixData(prefixVersionPrefix(gen), nil)
+		return err
+	}
+
+	var old uint64
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		var err error
+		if old, err = readPrefixPointer(txn, prefix); err != nil {
+			return err
+		}
+		return txn.Set(prefixPointerKey(prefix), []byte(strconv.FormatUint(gen, 16)))
+	})
+	if err != nil {
+		b.dropPrefixData(prefixVersionPrefix(gen), nil)
+		return err
+	}
+
+	b.addVersionedPrefix(prefix)
+	b.purgeReadCache()
+
+	// 旧版本不再被读取，在后台删除；首次切换时删除前缀下原有的普通key
+	stale := []byte(prefix)
+	if old != 0 {
+		stale = prefixVersionPrefix(old)
+	}
+	drop := func(stop <-chan struct{}) { b.dropPrefixData(stale, stop) }
+	if !b.startWorker(prefixDropWorker+strconv.FormatUint(gen, 16), drop) {
+		drop(nil)
+	}
+	return nil
+}
+
+// addVersionedPrefix 将前缀加入内存中的版本化前缀列表
+func (b *BadgerDB) addVersionedPrefix(prefix string) {
+	var prefixes []string
+	if current := b.versioned.Load(); current != nil {
+		for _, p := range *current {
+			if p == prefix {
+				return
+			}
+		}
+		prefixes = append(prefixes, *current...)
+	}
+	prefixes = append(prefixes, prefix)
+	b.versioned.Store(&prefixes)
+}
+
+// dropPrefixData 删除指定前缀下除内部保留key以外的全部key（prefix本身为保留前缀时删除其下全部key）
+// 通过写入删除标记完成，正在读取旧版本的事务不受影响；stop被关闭时提前结束
+func (b *BadgerDB) dropPrefixData(prefix []byte, stop <-chan struct{}) error {
+	wb := b.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		n := 0
+		for it.Rewind(); it.Valid(); it.Next() {
+			if n%deleteCheckInterval == 0 {
+				select {
+				case <-stop:
+					return nil
+				default:
+				}
+			}
+			n++
+
+			key := it.Item().Key()
+			if isReservedKey(key) && !isReservedKey(prefix) {
+				continue
+			}
+			if err := wb.Delete(it.Item().KeyCopy(nil)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return wb.Flush()
+}
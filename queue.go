@@ -0,0 +1,84 @@
+package rbadger
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// queuePrefix 队列使用的保留key前缀
+const queuePrefix = reservedPrefix + "queue:"
+
+// Queue 基于列表实现的持久化类型化先进先出队列
+// 元素使用 gob 编码后存储在保留前缀下，不会出现在普通扫描结果中
+type Queue[T any] struct {
+	db  *BadgerDB
+	key string
+}
+
+// NewQueue 创建一个名为name的队列，相同名称的队列共享同一份数据
+// 示例：
+//
+//	type Job struct {
+//	    ID   int
+//	    Name string
+//	}
+//	q := NewQueue[Job](db, "jobs")
+//	q.Enqueue(Job{ID: 1, Name: "send-mail"})
+//	job, ok, err := q.Dequeue()
+func NewQueue[T any](db *BadgerDB, name string) *Queue[T] {
+	return &Queue[T]{db: db, key: queuePrefix + name}
+}
+
+// Enqueue 将元素加入队尾
+func (q *Queue[T]) Enqueue(v T) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+
+	return q.db.updateWithRetry(func(txn *badger.Txn) error {
+		_, err := listPush(txn, q.key, false, buf.Bytes())
+		return err
+	})
+}
+
+// Dequeue 从队首取出一个元素，队列为空时ok为false
+// 读取、解码与删除在同一个事务中完成，并发调用时每个元素只会被取出一次；
+// 元素无法解码时返回错误，且元素保留在队列中
+func (q *Queue[T]) Dequeue() (v T, ok bool, err error) {
+	err = q.db.updateWithRetry(func(txn *badger.Txn) error {
+		var zero T
+		v, ok = zero, false
+
+		data, err := listPop(txn, q.key, true)
+		if err != nil || data == nil {
+			return err
+		}
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+	if ok {
+		q.db.noteDeletes(1)
+	}
+	return v, ok, nil
+}
+
+// Len 返回队列中的元素数量
+func (q *Queue[T]) Len() (int, error) {
+	var n int
+	err := q.db.db.View(func(txn *badger.Txn) error {
+		meta, err := readListMeta(txn, q.key)
+		n = int(meta.len())
+		return err
+	})
+	return n, err
+}
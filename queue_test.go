@@ -0,0 +1,107 @@
+package rbadger
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestQueue 测试Queue的入队与出队
+func TestQueue(t *testing.T) {
+	db := newTestDB(t)
+
+	type job struct {
+		ID   int
+		Name string
+	}
+
+	q := NewQueue[job](db, "jobs")
+	for i := 1; i <= 3; i++ {
+		if err := q.Enqueue(job{ID: i, Name: "job"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := q.Len(); n != 3 {
+		t.Errorf("队列长度不正确: %d", n)
+	}
+
+	for i := 1; i <= 3; i++ {
+		j, ok, err := q.Dequeue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || j.ID != i {
+			t.Errorf("期望取出ID=%d，实际为%+v, ok=%v", i, j, ok)
+		}
+	}
+
+	if _, ok, err := q.Dequeue(); err != nil || ok {
+		t.Errorf("空队列应返回ok=false，实际ok=%v, err=%v", ok, err)
+	}
+	if keys, _ := db.FindKeys(""); len(keys) != 0 {
+		t.Errorf("队列数据不应出现在扫描结果中: %v", keys)
+	}
+}
+
+// TestQueueConcurrentDequeue 测试并发出队时每个元素只被取出一次
+func TestQueueConcurrentDequeue(t *testing.T) {
+	db := newTestDB(t)
+
+	q := NewQueue[int](db, "nums")
+	for i := 0; i < 50; i++ {
+		if err := q.Enqueue(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok, err := q.Dequeue()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !ok {
+					return
+				}
+				mu.Lock()
+				if seen[v] {
+					t.Errorf("元素%d被重复取出", v)
+				}
+				seen[v] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 50 {
+		t.Errorf("期望取出50个元素，实际取出%d个", len(seen))
+	}
+}
+
+// TestQueueDequeueDecodeError 测试元素无法解码时保留在队列中
+func TestQueueDequeueDecodeError(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := NewQueue[string](db, "jobs").Enqueue("send-mail"); err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewQueue[int](db, "jobs")
+	if _, ok, err := q.Dequeue(); err == nil || ok {
+		t.Errorf("类型不匹配时应返回错误，实际ok=%v, err=%v", ok, err)
+	}
+	if n, _ := q.Len(); n != 1 {
+		t.Errorf("解码失败的元素应保留在队列中，队列长度: %d", n)
+	}
+
+	if v, ok, err := NewQueue[string](db, "jobs").Dequeue(); err != nil || !ok || v != "send-mail" {
+		t.Errorf("元素应可被正确取出: %q, %v, %v", v, ok, err)
+	}
+}
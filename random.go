@@ -0,0 +1,135 @@
+package rbadger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// randomKeysAttempts RandomKeys 每个结果最多尝试的随机定位次数，用于在key较少时限制重复抽样
+const randomKeysAttempts = 4
+
+// RandomKey 返回一个随机的已存在的key，数据库中没有key时返回 ErrNotFound
+// 实现方式是在首尾key之间随机定位后取下一个key，到达末尾时从头开始，
+// 因此结果只是近似随机：key在键空间中分布不均匀时，位于稀疏区间之后的key被选中的概率更高。
+// 适用于缓存淘汰实验、抽样检查等不要求均匀分布的场景
+// 示例：
+//
+//	key, err := db.RandomKey()
+//	if errors.Is(err, rbadger.ErrNotFound) {
+//	    fmt.Println("数据库为空")
+//	}
+func (b *BadgerDB) RandomKey() (string, error) {
+	keys, err := b.RandomKeys(1)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", ErrNotFound
+	}
+	return keys[0], nil
+}
+
+// RandomKeys 返回最多n个互不重复的随机key，抽样方式与 RandomKey 相同，结果同样只是近似随机
+// key的总数较少时返回的数量可能小于n，数据库中没有key时返回空切片
+// 示例：
+//
+//	keys, err := db.RandomKeys(10)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) RandomKeys(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	var keys []string
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false // 只需要key，不需要预取值
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		first, ok := nextUserKey(it, nil)
+		if !ok {
+			return nil
+		}
+		last := lastUserKey(txn)
+
+		seen := make(map[string]struct{}, n)
+		for i := 0; i < n*randomKeysAttempts && len(keys) < n; i++ {
+			key, _ := nextUserKey(it, randomSeekKey([]byte(first), last))
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// lastUserKey 返回数据库中最大的非内部key
+func lastUserKey(txn *badger.Txn) []byte {
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Reverse = true
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		if !isReservedKey(it.Item().Key()) {
+			return it.Item().KeyCopy(nil)
+		}
+	}
+	return nil
+}
+
+// nextUserKey 返回从seek开始的第一个非内部key，到达末尾时从头继续查找
+func nextUserKey(it *badger.Iterator, seek []byte) (string, bool) {
+	for it.Seek(seek); it.Valid(); it.Next() {
+		if !isReservedKey(it.Item().Key()) {
+			return string(it.Item().Key()), true
+		}
+	}
+	for it.Rewind(); it.Valid() && bytes.Compare(it.Item().Key(), seek) < 0; it.Next() {
+		if !isReservedKey(it.Item().Key()) {
+			return string(it.Item().Key()), true
+		}
+	}
+	return "", false
+}
+
+// randomSeekKey 以key的前8个字节作为大端整数，在first与last之间随机生成一个定位用的key
+func randomSeekKey(first, last []byte) []byte {
+	lo, hi := keyPrefixUint64(first), keyPrefixUint64(last)
+	if hi <= lo {
+		return first
+	}
+
+	// 范围覆盖整个uint64时 hi-lo+1 会溢出为0
+	var offset uint64
+	if hi-lo == math.MaxUint64 {
+		offset = rand.Uint64()
+	} else {
+		offset = rand.Uint64N(hi - lo + 1)
+	}
+
+	seek := make([]byte, 8)
+	binary.BigEndian.PutUint64(seek, lo+offset)
+	return seek
+}
+
+// keyPrefixUint64 将key的前8个字节按大端序转换为整数，不足8个字节时在末尾补0
+func keyPrefixUint64(key []byte) uint64 {
+	var buf [8]byte
+	copy(buf[:], key)
+	return binary.BigEndian.Uint64(buf[:])
+}
@@ -0,0 +1,85 @@
+package rbadger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestRandomKey 测试RandomKey和RandomKeys方法
+func TestRandomKey(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.RandomKey(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("数据库为空时应返回ErrNotFound: %v", err)
+	}
+	if err := db.SetSchemaVersion(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.RandomKey(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不应返回内部使用的key: %v", err)
+	}
+
+	existing := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("user:%02d", i)
+		if err := db.SetS(key, "v"); err != nil {
+			t.Fatal(err)
+		}
+		existing[key] = true
+	}
+
+	picked := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key, err := db.RandomKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !existing[key] {
+			t.Fatalf("返回了不存在的key: %q", key)
+		}
+		picked[key] = true
+	}
+	if len(picked) < 2 {
+		t.Errorf("多次抽样应返回不同的key, 实际: %v", picked)
+	}
+
+	keys, err := db.RandomKeys(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) == 0 || len(keys) > 5 {
+		t.Errorf("RandomKeys返回数量不正确: %v", keys)
+	}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if !existing[key] || seen[key] {
+			t.Errorf("RandomKeys返回了不存在或重复的key: %v", keys)
+		}
+		seen[key] = true
+	}
+}
+
+// TestRandomKeyFullRange 测试首尾key覆盖整个键空间时不会溢出
+func TestRandomKeyFullRange(t *testing.T) {
+	db := newTestDB(t)
+
+	first := make([]byte, 8)
+	last := bytes.Repeat([]byte{0xff}, 8)
+	for _, key := range [][]byte{first, last} {
+		if err := db.SetBytes(key, []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		key, err := db.RandomKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != string(first) && key != string(last) {
+			t.Fatalf("返回了不存在的key: %q", key)
+		}
+	}
+}
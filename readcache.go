@@ -0,0 +1,162 @@
+package rbadger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// readCache 位于 Get 之前的进程内LRU缓存
+type readCache struct {
+	mu         sync.Mutex
+	gen        uint64 // 每次失效时递增，用于丢弃失效前开始的读取结果
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// readCacheEntry 读缓存中的一项
+type readCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func newReadCache(maxEntries int, ttl time.Duration) *readCache {
+	return &readCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// get 获取缓存的值，不存在或已过期时返回false
+func (c *readCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*readCacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// generation 返回当前的失效代数
+func (c *readCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// add 添加或更新缓存的值，超过容量时淘汰最久未使用的项
+// gen 为读取数据库前获取的失效代数，期间发生过失效时放弃添加，避免缓存旧值
+func (c *readCache) add(key string, value []byte, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	expires := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*readCacheEntry)
+		entry.value = value
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&readCacheEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.maxEntries {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*readCacheEntry).key)
+	}
+}
+
+// remove 删除缓存的值
+func (c *readCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// purge 清空缓存
+func (c *readCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// EnableReadCache 在 Get/GetS/GetBytes 之前启用进程内的LRU读缓存
+// 最多缓存maxEntries个key，每项缓存ttl后失效（ttl<=0表示不按时间失效）；
+// 通过本实例写入或删除key时会同步使对应的缓存失效。
+// 其他进程对同一数据库的写入无法感知，因此仅适用于单写入者的场景。
+// maxEntries<=0 表示关闭读缓存
+// 示例：
+//
+//	db.EnableReadCache(1000, time.Minute)
+func (b *BadgerDB) EnableReadCache(maxEntries int, ttl time.Duration) {
+	if maxEntries <= 0 {
+		b.rcache.Store(nil)
+		return
+	}
+	b.rcache.Store(newReadCache(maxEntries, ttl))
+}
+
+// cacheGet 从读缓存中获取key的值
+// 未命中时返回的gen需在读取数据库后传给 cacheAdd
+func (b *BadgerDB) cacheGet(key []byte) (value []byte, gen uint64, ok bool) {
+	rc := b.rcache.Load()
+	if rc == nil {
+		return nil, 0, false
+	}
+	if value, ok = rc.get(string(key)); ok {
+		return append([]byte{}, value...), 0, true
+	}
+	return nil, rc.generation(), false
+}
+
+// cacheAdd 将从数据库读取的值加入读缓存
+func (b *BadgerDB) cacheAdd(key, value []byte, gen uint64) {
+	if rc := b.rcache.Load(); rc != nil {
+		rc.add(string(key), append([]byte{}, value...), gen)
+	}
+}
+
+// invalidate 使给定key的读缓存失效
+func (b *BadgerDB) invalidate(keys ...string) {
+	rc := b.rcache.Load()
+	if rc == nil {
+		return
+	}
+	for _, key := range keys {
+		rc.remove(key)
+	}
+}
+
+// purgeReadCache 清空读缓存，用于批量写入等无法逐个确定key的场景
+func (b *BadgerDB) purgeReadCache() {
+	if rc := b.rcache.Load(); rc != nil {
+		rc.purge()
+	}
+}
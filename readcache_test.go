@@ -0,0 +1,71 @@
+package rbadger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReadCache 测试读缓存的命中与失效
+func TestReadCache(t *testing.T) {
+	db := newTestDB(t)
+	db.EnableReadCache(2, time.Minute)
+
+	if err := db.SetS("k1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.GetS("k1"); err != nil || v != "v1" {
+		t.Fatalf("获取失败: %q, %v", v, err)
+	}
+	if _, ok := db.rcache.Load().get("k1"); !ok {
+		t.Error("读取后应被缓存")
+	}
+
+	// 写入后缓存失效
+	if err := db.SetS("k1", "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := db.GetS("k1"); v != "v2" {
+		t.Errorf("写入后应读取新值: %q", v)
+	}
+
+	// 删除后缓存失效
+	if err := db.Del("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.GetS("k1"); err == nil {
+		t.Error("删除后不应读取到值")
+	}
+
+	// 超过容量时淘汰最久未使用的项
+	for _, key := range []string{"a", "b", "c"} {
+		if err := db.SetS(key, key); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.GetS(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := db.rcache.Load().get("a"); ok {
+		t.Error("a应已被淘汰")
+	}
+	if _, ok := db.rcache.Load().get("c"); !ok {
+		t.Error("c应在缓存中")
+	}
+
+	// 关闭读缓存
+	db.EnableReadCache(0, 0)
+	if db.rcache.Load() != nil {
+		t.Error("读缓存应已关闭")
+	}
+}
+
+// TestReadCacheStaleAdd 测试失效后不会写入失效前读取的旧值
+func TestReadCacheStaleAdd(t *testing.T) {
+	c := newReadCache(10, 0)
+	gen := c.generation()
+	c.remove("k")
+	c.add("k", []byte("old"), gen)
+	if _, ok := c.get("k"); ok {
+		t.Error("失效前读取的值不应被缓存")
+	}
+}
@@ -0,0 +1,142 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// RekeyConflict 指定 RekeyPrefix 遇到目标key已存在时的处理方式
+type RekeyConflict int
+
+const (
+	// RekeyError 目标key已存在时停止迁移并返回 ErrKeyExists
+	RekeyError RekeyConflict = iota
+	// RekeySkip 目标key已存在时跳过，原key保持不变
+	RekeySkip
+	// RekeyOverwrite 目标key已存在时用原key的值覆盖
+	RekeyOverwrite
+)
+
+// RekeyPrefix 将oldPrefix下的所有key迁移到newPrefix下，返回迁移的数量
+// 值、过期时间（包括 SetExIndexed 记录在索引中的过期时间）和元数据原样保留，
+// 写入新key与删除旧key在同一个事务中完成，按批提交。
+// 已迁移的key不再位于oldPrefix下，因此中断后重新执行会从剩余的key继续，重复执行是安全的。
+// 目标key已存在时按onConflict处理；newPrefix不能以oldPrefix开头
+// 示例：
+//
+//	n, err := db.RekeyPrefix("u:", "user:", rbadger.RekeyError)
+//	if err != nil {
+//	    log.Printf("迁移中断: %v，已迁移%d个", err, n)
+//	}
+func (b *BadgerDB) RekeyPrefix(oldPrefix, newPrefix string, onConflict RekeyConflict) (int, error) {
+	if strings.HasPrefix(newPrefix, oldPrefix) {
+		return 0, errors.New("rbadger: new prefix must not start with old prefix")
+	}
+	defer b.purgeReadCache()
+
+	moved := 0
+	seek := []byte(oldPrefix)
+	for {
+		var n int
+		var last []byte
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			n, last = 0, nil
+
+			type entry struct {
+				key       []byte
+				value     []byte
+				expiresAt uint64
+				meta      byte
+			}
+			var batch []entry
+
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte(oldPrefix)
+			it := txn.NewIterator(opts)
+			for it.Seek(seek); it.Valid() && len(batch) < expireBatchSize; it.Next() {
+				item := it.Item()
+				if isReservedKey(item.Key()) {
+					continue
+				}
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					it.Close()
+					return err
+				}
+				batch = append(batch, entry{
+					key:       item.KeyCopy(nil),
+					value:     value,
+					expiresAt: item.ExpiresAt(),
+					meta:      item.UserMeta(),
+				})
+			}
+			it.Close()
+
+			for _, e := range batch {
+				last = e.key
+				newKey := []byte(newPrefix + strings.TrimPrefix(string(e.key), oldPrefix))
+
+				if onConflict != RekeyOverwrite {
+					_, err := txn.Get(newKey)
+					if err == nil {
+						if onConflict == RekeySkip {
+							continue
+						}
+						return fmt.Errorf("%w: %q", ErrKeyExists, newKey)
+					}
+					if err != badger.ErrKeyNotFound {
+						return err
+					}
+				}
+
+				ne := badger.NewEntry(newKey, e.value).WithMeta(e.meta)
+				ne.ExpiresAt = e.expiresAt
+				if err := txn.SetEntry(ne); err != nil {
+					return err
+				}
+				if err := txn.Delete(e.key); err != nil {
+					return err
+				}
+				if err := moveTTLIndex(txn, string(e.key), string(newKey)); err != nil {
+					return err
+				}
+				n++
+			}
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+		if last == nil {
+			return moved, nil
+		}
+
+		// 从本批最后一个key之后继续，跳过的key仍留在原处
+		seek = append(last, 0)
+	}
+}
+
+// moveTTLIndex 将oldKey的过期时间索引移动到newKey下
+// oldKey没有索引时删除newKey可能残留的索引，避免迁移后的值被视为已过期
+func moveTTLIndex(txn *badger.Txn, oldKey, newKey string) error {
+	item, err := txn.Get(ttlIndexKey(oldKey))
+	if err == badger.ErrKeyNotFound {
+		return txn.Delete(ttlIndexKey(newKey))
+	}
+	if err != nil {
+		return err
+	}
+
+	expire, err := item.ValueCopy(nil)
+	if err != nil {
+		return err
+	}
+	if err := txn.Set(ttlIndexKey(newKey), expire); err != nil {
+		return err
+	}
+	return txn.Delete(ttlIndexKey(oldKey))
+}
@@ -0,0 +1,89 @@
+package rbadger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestRekeyPrefix 测试前缀迁移
+func TestRekeyPrefix(t *testing.T) {
+	db := newTestDB(t)
+
+	db.SetS("u:1", "a")
+	db.XSetExS("u:2", "b", time.Hour)
+	db.SetS("u:3", "c")
+	db.SetS("user:3", "exists")
+
+	if _, err := db.RekeyPrefix("u:", "user:", RekeyError); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("目标key已存在时应返回ErrKeyExists: %v", err)
+	}
+	if v, _ := db.GetS("user:3"); v != "exists" {
+		t.Error("出错的批次不应产生修改")
+	}
+
+	n, err := db.RekeyPrefix("u:", "user:", RekeySkip)
+	if err != nil || n != 2 {
+		t.Fatalf("迁移结果不正确: %d, %v", n, err)
+	}
+	if v, _ := db.GetS("user:1"); v != "a" {
+		t.Errorf("user:1的值不正确: %q", v)
+	}
+	if ttl, _ := db.XTTL("user:2"); ttl <= 0 {
+		t.Errorf("应保留过期时间: %d", ttl)
+	}
+	if v, _ := db.GetS("u:3"); v != "c" {
+		t.Error("跳过的key应保留在原处")
+	}
+
+	// 重复执行时只处理剩余的key
+	n, err = db.RekeyPrefix("u:", "user:", RekeyOverwrite)
+	if err != nil || n != 1 {
+		t.Fatalf("重复迁移结果不正确: %d, %v", n, err)
+	}
+	if v, _ := db.GetS("user:3"); v != "c" {
+		t.Errorf("应覆盖已存在的key: %q", v)
+	}
+	if keys, _ := db.FindKeys("u:"); len(keys) != 0 {
+		t.Errorf("原前缀下不应再有key: %v", keys)
+	}
+
+	if _, err := db.RekeyPrefix("u:", "u:new:", RekeyError); err == nil {
+		t.Error("新前缀以旧前缀开头时应返回错误")
+	}
+}
+
+// TestRekeyPrefixTTLIndex 测试迁移时同时移动独立过期时间索引
+func TestRekeyPrefixTTLIndex(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetExIndexed("u:1", []byte("a"), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := db.RekeyPrefix("u:", "user:", RekeyError); err != nil || n != 1 {
+		t.Fatalf("迁移结果不正确: %d, %v", n, err)
+	}
+
+	var expire int64
+	err := db.db.View(func(txn *badger.Txn) error {
+		var err error
+		expire, err = readTTLIndex(txn, "user:1")
+		return err
+	})
+	if err != nil || expire <= time.Now().Unix() {
+		t.Errorf("过期时间索引应移动到新key下: %d, %v", expire, err)
+	}
+
+	// 旧key下不应残留索引，重新写入的值不会被视为已过期
+	if err := db.Set("u:1", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.GetExIndexed("u:1"); err != nil || string(v) != "new" {
+		t.Errorf("旧key下残留了过期时间索引: %q, %v", v, err)
+	}
+	if v, err := db.GetExIndexed("user:1"); err != nil || string(v) != "a" {
+		t.Errorf("迁移后的值不正确: %q, %v", v, err)
+	}
+}
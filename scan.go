@@ -0,0 +1,507 @@
+package rbadger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	"golang.org/x/sync/errgroup"
+)
+
+// ScanMixed 在一次遍历中扫描指定前缀下的所有key，并区分普通数据与带过期时间的缓存数据
+// 能解码为 CacheType 的key以isCache=true回调，value为其中的数据，ttl与 XTTL 的含义相同
+// （-1表示未设置过期时间）；已过期的缓存数据会被跳过。其他key以isCache=false回调，
+// value为原始数据，ttl为-1。value仅在回调期间有效，需要保留时请复制。
+// fn返回错误时停止扫描并返回该错误
+// 示例：
+//
+//	err := db.ScanMixed("app:", func(key string, value []byte, isCache bool, ttl int64) error {
+//	    fmt.Printf("%s cache=%v ttl=%d len=%d\n", key, isCache, ttl, len(value))
+//	    return nil
+//	})
+func (b *BadgerDB) ScanMixed(prefix string, fn func(key string, value []byte, isCache bool, ttl int64) error) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		now := time.Now().Unix()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				key := string(item.Key())
+				cache, err := b.decodeCache(val)
+				if err != nil {
+					return fn(key, val, false, -1)
+				}
+				if cache.expiredAt(now) {
+					return nil
+				}
+				ttl := int64(-1)
+				if cache.Expire > 0 {
+					ttl = cache.Expire - now
+				}
+				return fn(key, cache.Data, true, ttl)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// KeySizes 返回指定前缀下每个key对应值的字节数
+// 使用 item.ValueSize 获取大小，不会从值日志中读取值本身，适合查找占用空间较大的key。
+// 带过期时间存储的key返回的是编码后的大小。通过 WithMaxResultBytes 设置上限后，
+// 结果中key的总字节数超过上限时返回 ErrResultTooLarge
+// 示例：
+//
+//	sizes, err := db.KeySizes("blob:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for key, size := range sizes {
+//	    fmt.Printf("%s: %d字节\n", key, size)
+//	}
+func (b *BadgerDB) KeySizes(prefix string) (map[string]int, error) {
+	sizes := make(map[string]int)
+	var total int
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			key := string(item.Key())
+			total += len(key)
+			if b.cfg.maxResultBytes > 0 && total > b.cfg.maxResultBytes {
+				return ErrResultTooLarge
+			}
+			sizes[key] = int(item.ValueSize())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+// ScanSharded 将整个key空间按首字节划分为shards个区间，并为每个区间启动一个goroutine并发遍历
+// 每个key只会出现在一个分片中，fn会被多个goroutine并发调用，需要自行保证并发安全；
+// value仅在回调期间有效。任意分片的fn返回错误时，其他分片尽快停止，并返回第一个错误。
+// shards 小于1时按1处理，大于256时按256处理
+// 示例：
+//
+//	var count atomic.Int64
+//	err := db.ScanSharded(runtime.NumCPU(), func(shard int, key string, value []byte) error {
+//	    count.Add(1)
+//	    return nil
+//	})
+func (b *BadgerDB) ScanSharded(shards int, fn func(shard int, key string, value []byte) error) error {
+	if shards < 1 {
+		shards = 1
+	}
+	if shards > 256 {
+		shards = 256
+	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	for shard := 0; shard < shards; shard++ {
+		// 分片覆盖首字节在 [lo, hi) 范围内的key，最后一个分片没有上界
+		lo := shard * 256 / shards
+		hi := (shard + 1) * 256 / shards
+		last := shard == shards-1
+
+		g.Go(func() error {
+			return b.db.View(func(txn *badger.Txn) error {
+				it := txn.NewIterator(badger.DefaultIteratorOptions)
+				defer it.Close()
+
+				n := 0
+				for it.Seek([]byte{byte(lo)}); it.Valid(); it.Next() {
+					item := it.Item()
+					if !last && int(item.Key()[0]) >= hi {
+						return nil
+					}
+					if n++; n%deleteCheckInterval == 0 {
+						if err := ctx.Err(); err != nil {
+							return err
+						}
+					}
+					if isReservedKey(item.Key()) {
+						continue
+					}
+					err := item.Value(func(val []byte) error {
+						return fn(shard, string(item.Key()), val)
+					})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	}
+	return g.Wait()
+}
+
+// ScanKeys 分页扫描匹配指定前缀的key
+// cursor 为上一页最后一个key（不包含在结果中），为空时从前缀开始扫描；
+// 每页最多返回limit个key，返回的nextCursor为空表示已经没有更多数据
+// 示例：
+//
+//	cursor := ""
+//	for {
+//	    keys, next, err := db.ScanKeys("user:", cursor, 100)
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    // 处理keys
+//	    if next == "" {
+//	        break
+//	    }
+//	    cursor = next
+//	}
+func (b *BadgerDB) ScanKeys(prefix string, cursor string, limit int) (keys []string, nextCursor string, err error) {
+	return b.ScanKeysCtx(context.Background(), prefix, cursor, limit)
+}
+
+// ScanKeysCtx 与 ScanKeys 相同，但每遍历1000个key检查一次ctx，ctx结束时停止扫描并返回包装后的ctx错误
+// 示例：
+//
+//	keys, next, err := db.ScanKeysCtx(ctx, "user:", cursor, 100)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//	    log.Println("扫描超时")
+//	}
+func (b *BadgerDB) ScanKeysCtx(ctx context.Context, prefix string, cursor string, limit int) (keys []string, nextCursor string, err error) {
+	if limit <= 0 {
+		return nil, "", errors.New("rbadger: limit must be positive")
+	}
+
+	err = b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		if cursor == "" {
+			it.Rewind()
+		} else {
+			it.Seek(append([]byte(cursor), 0))
+		}
+		for n := 0; it.Valid(); it.Next() {
+			if n%scanCheckInterval == 0 {
+				if err := checkCtx(ctx, "scan keys"); err != nil {
+					return err
+				}
+			}
+			n++
+
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			if len(keys) == limit {
+				// 还有更多数据，以本页最后一个key作为游标
+				nextCursor = keys[len(keys)-1]
+				return nil
+			}
+			keys = append(keys, string(item.Key()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, nextCursor, nil
+}
+
+// prefixUpperBound 返回大于所有以prefix开头的key的最小值，prefix为空或全为0xFF时返回nil
+func prefixUpperBound(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xFF {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// seekLastInPrefix 将反向迭代器定位到以prefix开头的最后一个key
+// 反向迭代时 Seek 会定位到小于等于目标的最大key，因此不能直接 Seek(prefix)，
+// 而是定位到前缀的上界，并跳过恰好等于上界的key。
+// 迭代器不能设置 opts.Prefix，否则无法判断是否停在了上界上，需由调用方使用 ValidForPrefix 判断
+func seekLastInPrefix(it *badger.Iterator, prefix []byte) {
+	end := prefixUpperBound(prefix)
+	if end == nil {
+		it.Rewind()
+		return
+	}
+	it.Seek(end)
+	if it.Valid() && bytes.Equal(it.Item().Key(), end) {
+		it.Next()
+	}
+}
+
+// FindKeysReverse 按从大到小的顺序返回所有匹配指定前缀的key
+// 适合 log:<时间戳> 这类按时间排序的key，最新的排在最前面。
+// 通过 WithMaxResultBytes 设置上限后，结果超过上限时返回 ErrResultTooLarge
+// 示例：
+//
+//	keys, err := db.FindKeysReverse("log:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) FindKeysReverse(prefix string) ([]string, error) {
+	var keys []string
+	var size int
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefixBytes := []byte(prefix)
+		for seekLastInPrefix(it, prefixBytes); it.ValidForPrefix(prefixBytes); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			key := string(item.Key())
+			size += len(key)
+			if b.cfg.maxResultBytes > 0 && size > b.cfg.maxResultBytes {
+				return ErrResultTooLarge
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ScanKeysReverse 与 ScanKeys 相同，但按从大到小的顺序分页扫描
+// cursor 为上一页最后一个key（不包含在结果中），为空时从前缀的最后一个key开始
+// 示例：
+//
+//	keys, next, err := db.ScanKeysReverse("log:", "", 50)
+func (b *BadgerDB) ScanKeysReverse(prefix string, cursor string, limit int) (keys []string, nextCursor string, err error) {
+	if limit <= 0 {
+		return nil, "", errors.New("rbadger: limit must be positive")
+	}
+
+	err = b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		prefixBytes := []byte(prefix)
+		if cursor == "" {
+			seekLastInPrefix(it, prefixBytes)
+		} else {
+			it.Seek([]byte(cursor))
+			if it.Valid() && string(it.Item().Key()) == cursor {
+				it.Next()
+			}
+		}
+		for ; it.ValidForPrefix(prefixBytes); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			if len(keys) == limit {
+				nextCursor = keys[len(keys)-1]
+				return nil
+			}
+			keys = append(keys, string(item.Key()))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, nextCursor, nil
+}
+
+// FindKeyValues 在一次遍历中返回匹配指定前缀的所有key及其值
+// 通过 WithMaxResultBytes 设置上限后，结果中key与值的总字节数超过上限时返回 ErrResultTooLarge
+// 示例：
+//
+//	kvs, err := db.FindKeyValues("user:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for key, value := range kvs {
+//	    fmt.Printf("%s: %s\n", key, value)
+//	}
+func (b *BadgerDB) FindKeyValues(prefix string) (map[string][]byte, error) {
+	result := make(map[string][]byte)
+	var size int
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			key := string(item.Key())
+			size += len(key) + int(item.ValueSize())
+			if b.cfg.maxResultBytes > 0 && size > b.cfg.maxResultBytes {
+				return ErrResultTooLarge
+			}
+			// 迭代器会复用缓冲区，必须复制值
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			result[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FindXKeyValues 在一次遍历中返回匹配指定前缀且未过期的带过期时间存储的key及其数据
+// 无法解码为 CacheType 的key会被跳过，已过期的key会在遍历后被删除。
+// 通过 WithMaxResultBytes 设置上限后，结果中key与数据的总字节数超过上限时返回 ErrResultTooLarge
+// 示例：
+//
+//	kvs, err := db.FindXKeyValues("cache:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) FindXKeyValues(prefix string) (map[string][]byte, error) {
+	result := make(map[string][]byte)
+	var expiredKeys []string
+	var size int
+
+	now := time.Now().Unix()
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			key := string(item.Key())
+			err := item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err != nil {
+					return nil
+				}
+				if cache.expiredAt(now) {
+					expiredKeys = append(expiredKeys, key)
+					return nil
+				}
+				size += len(key) + len(cache.Data)
+				if b.cfg.maxResultBytes > 0 && size > b.cfg.maxResultBytes {
+					return ErrResultTooLarge
+				}
+				// 解码得到的数据不引用迭代器的缓冲区，无需再次复制
+				result[key] = cache.Data
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.expireKeys(expiredKeys...)
+	return result, nil
+}
+
+// ForEach 依次对匹配指定前缀的每个key调用fn，不缓存扫描结果，内存占用与数据量无关
+// value仅在回调期间有效，需要保留时请复制。fn返回 ErrStopIteration 时停止扫描并返回nil，
+// 返回其他错误时停止扫描并返回该错误
+// 示例：
+//
+//	err := db.ForEach("order:", func(key string, value []byte) error {
+//	    if done {
+//	        return rbadger.ErrStopIteration
+//	    }
+//	    return process(key, value)
+//	})
+func (b *BadgerDB) ForEach(prefix string, fn func(key string, value []byte) error) error {
+	return b.ForEachCtx(context.Background(), prefix, fn)
+}
+
+// ForEachCtx 与 ForEach 相同，但每遍历1000个key检查一次ctx，ctx结束时停止扫描并返回包装后的ctx错误
+// 示例：
+//
+//	err := db.ForEachCtx(ctx, "order:", func(key string, value []byte) error {
+//	    return process(key, value)
+//	})
+//	if errors.Is(err, context.Canceled) {
+//	    log.Println("扫描已取消")
+//	}
+func (b *BadgerDB) ForEachCtx(ctx context.Context, prefix string, fn func(key string, value []byte) error) error {
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		n := 0
+		for it.Rewind(); it.Valid(); it.Next() {
+			if n%scanCheckInterval == 0 {
+				if err := checkCtx(ctx, "for each"); err != nil {
+					return err
+				}
+			}
+			n++
+
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				return fn(string(item.Key()), val)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrStopIteration) {
+		return nil
+	}
+	return err
+}
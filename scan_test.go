@@ -1,10 +1,14 @@
 package rbadger
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/dgraph-io/badger/v4"
 )
 
 // TestKeys 测试Keys方法
@@ -21,11 +25,11 @@ func TestKeys(t *testing.T) {
 
 	// 设置一些测试数据
 	testData := map[string]string{
-		"user:1": "alice",
-		"user:2": "bob",
-		"user:3": "charlie",
-		"order:1": "order1",
-		"order:2": "order2",
+		"user:1":    "alice",
+		"user:2":    "bob",
+		"user:3":    "charlie",
+		"order:1":   "order1",
+		"order:2":   "order2",
 		"product:1": "product1",
 	}
 
@@ -169,4 +173,288 @@ func TestMixedKeys(t *testing.T) {
 	if len(cacheKeys) != 2 {
 		t.Errorf("期望找到2个缓存类型的test key，实际找到%d个", len(cacheKeys))
 	}
-}
\ No newline at end of file
+}
+
+// TestFindKeysMaxResultBytes 测试FindKeys的结果大小上限
+func TestFindKeysMaxResultBytes(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts, WithMaxResultBytes(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := db.SetS(fmt.Sprintf("user:%d", i), "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 每个key 6字节，3个key共18字节，未超过上限
+	if keys, err := db.FindKeys("user:"); err != nil || len(keys) != 3 {
+		t.Errorf("期望找到3个key，实际为%v, err=%v", keys, err)
+	}
+
+	if err := db.SetS("user:3", "v"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.FindKeys("user:"); err != ErrResultTooLarge {
+		t.Errorf("期望返回ErrResultTooLarge，实际为%v", err)
+	}
+}
+
+// TestScanMixed 测试ScanMixed方法
+func TestScanMixed(t *testing.T) {
+	db := newTestDB(t)
+
+	db.SetS("app:plain", "raw")
+	db.XSetS("app:cache", "data")
+	db.XSetExS("app:ttl", "data", time.Hour)
+	db.XSetExSecS("app:expired", "data", 1)
+	time.Sleep(1100 * time.Millisecond)
+
+	type result struct {
+		value   string
+		isCache bool
+		ttl     int64
+	}
+	got := make(map[string]result)
+	err := db.ScanMixed("app:", func(key string, value []byte, isCache bool, ttl int64) error {
+		got[key] = result{string(value), isCache, ttl}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 {
+		t.Errorf("期望3个key，实际为%v", got)
+	}
+	if r := got["app:plain"]; r.isCache || r.value != "raw" || r.ttl != -1 {
+		t.Errorf("普通数据不正确: %+v", r)
+	}
+	if r := got["app:cache"]; !r.isCache || r.value != "data" || r.ttl != -1 {
+		t.Errorf("永不过期的缓存数据不正确: %+v", r)
+	}
+	if r := got["app:ttl"]; !r.isCache || r.ttl <= 0 || r.ttl > 3600 {
+		t.Errorf("带过期时间的缓存数据不正确: %+v", r)
+	}
+}
+
+// TestKeySizes 测试KeySizes方法
+func TestKeySizes(t *testing.T) {
+	db := newTestDB(t)
+
+	db.SetS("blob:small", "abc")
+	db.Set("blob:large", make([]byte, 4096))
+	db.SetS("other", "x")
+
+	sizes, err := db.KeySizes("blob:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 || sizes["blob:small"] != 3 || sizes["blob:large"] != 4096 {
+		t.Errorf("结果不正确: %v", sizes)
+	}
+}
+
+// TestScanSharded 测试分片遍历时每个key只出现在一个分片中
+func TestScanSharded(t *testing.T) {
+	db := newTestDB(t)
+
+	const total = 500
+	for i := 0; i < total; i++ {
+		key := []byte{byte(i % 256), 'k', byte(i / 256)}
+		if err := db.SetBytes(key, []byte("v")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.SetMeta("m", []byte("v"))
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	err := db.ScanSharded(7, func(shard int, key string, value []byte) error {
+		if lo, hi := shard*256/7, (shard+1)*256/7; int(key[0]) < lo || (shard < 6 && int(key[0]) >= hi) {
+			t.Errorf("key %q 不属于分片%d", key, shard)
+		}
+		mu.Lock()
+		seen[key]++
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != total {
+		t.Errorf("期望%d个key，实际为%d个", total, len(seen))
+	}
+	for key, n := range seen {
+		if n != 1 {
+			t.Errorf("key %q 出现了%d次", key, n)
+		}
+	}
+
+	errStop := errors.New("stop")
+	if err := db.ScanSharded(4, func(int, string, []byte) error { return errStop }); err != errStop {
+		t.Errorf("应返回fn的错误: %v", err)
+	}
+}
+
+// TestCountKeys 测试CountKeys和CountXKeys方法
+func TestCountKeys(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 5; i++ {
+		db.SetS(fmt.Sprintf("user:%d", i), "v")
+	}
+	db.XSetS("cache:1", "v")
+	db.XSetExS("cache:2", "v", time.Hour)
+	db.XSetExSecS("cache:3", "v", 1)
+	db.SetS("cache:raw", "v")
+	time.Sleep(1100 * time.Millisecond)
+
+	if n, err := db.CountKeys("user:"); err != nil || n != 5 {
+		t.Errorf("CountKeys结果不正确: %d, %v", n, err)
+	}
+	if n, err := db.CountKeys("cache:"); err != nil || n != 4 {
+		t.Errorf("CountKeys结果不正确: %d, %v", n, err)
+	}
+	if n, err := db.CountXKeys("cache:"); err != nil || n != 2 {
+		t.Errorf("CountXKeys结果不正确: %d, %v", n, err)
+	}
+}
+
+// TestScanKeys 测试ScanKeys分页扫描
+func TestScanKeys(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 25; i++ {
+		db.SetS(fmt.Sprintf("user:%02d", i), "v")
+	}
+	db.SetS("userx", "v")
+
+	var all []string
+	cursor := ""
+	pages := 0
+	for {
+		keys, next, err := db.ScanKeys("user:", cursor, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		all = append(all, keys...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if pages != 3 || len(all) != 25 {
+		t.Errorf("分页结果不正确: %d页，%d个key", pages, len(all))
+	}
+	for i, key := range all {
+		if key != fmt.Sprintf("user:%02d", i) {
+			t.Errorf("第%d个key不正确: %s", i, key)
+		}
+	}
+
+	// 恰好为整页时不应返回游标
+	if keys, next, _ := db.ScanKeys("user:", "user:14", 10); len(keys) != 10 || next != "" {
+		t.Errorf("最后一页结果不正确: %v, %q", keys, next)
+	}
+}
+
+// TestFindKeysReverse 测试反向扫描
+func TestFindKeysReverse(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 1; i <= 5; i++ {
+		db.SetS(fmt.Sprintf("log:%d", i), "v")
+	}
+	// 前缀之外的相邻key不应出现在结果中
+	db.SetS("log;", "v")
+	db.SetS("log", "v")
+	db.SetBytes([]byte("log:\xff\xff"), []byte("v"))
+
+	keys, err := db.FindKeysReverse("log:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"log:\xff\xff", "log:5", "log:4", "log:3", "log:2", "log:1"}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("反向扫描结果不正确: %q", keys)
+	}
+
+	page1, next, err := db.ScanKeysReverse("log:", "", 3)
+	if err != nil || len(page1) != 3 || page1[2] != "log:4" || next != "log:4" {
+		t.Fatalf("第一页结果不正确: %q, %q, %v", page1, next, err)
+	}
+	page2, next, err := db.ScanKeysReverse("log:", next, 3)
+	if err != nil || fmt.Sprint(page2) != fmt.Sprint(want[3:]) || next != "" {
+		t.Errorf("第二页结果不正确: %q, %q, %v", page2, next, err)
+	}
+}
+
+// TestFindKeyValues 测试FindKeyValues和FindXKeyValues方法
+func TestFindKeyValues(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 3; i++ {
+		db.SetS(fmt.Sprintf("user:%d", i), fmt.Sprintf("v%d", i))
+	}
+	kvs, err := db.FindKeyValues("user:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 3 || string(kvs["user:0"]) != "v0" || string(kvs["user:2"]) != "v2" {
+		t.Errorf("FindKeyValues结果不正确: %q", kvs)
+	}
+
+	db.XSetS("cache:1", "a")
+	db.XSetExS("cache:2", "b", time.Hour)
+	db.XSetExSecS("cache:3", "c", 1)
+	db.SetS("cache:raw", "raw")
+	time.Sleep(1100 * time.Millisecond)
+
+	xkvs, err := db.FindXKeyValues("cache:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xkvs) != 2 || string(xkvs["cache:1"]) != "a" || string(xkvs["cache:2"]) != "b" {
+		t.Errorf("FindXKeyValues结果不正确: %q", xkvs)
+	}
+}
+
+// TestForEach 测试ForEach方法
+func TestForEach(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 10; i++ {
+		db.SetS(fmt.Sprintf("order:%d", i), "v")
+	}
+
+	n := 0
+	err := db.ForEach("order:", func(key string, value []byte) error {
+		n++
+		return nil
+	})
+	if err != nil || n != 10 {
+		t.Errorf("遍历结果不正确: %d, %v", n, err)
+	}
+
+	n = 0
+	err = db.ForEach("order:", func(key string, value []byte) error {
+		if n++; n == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || n != 3 {
+		t.Errorf("ErrStopIteration应停止遍历且不返回错误: %d, %v", n, err)
+	}
+
+	errFail := errors.New("fail")
+	if err := db.ForEach("order:", func(string, []byte) error { return errFail }); err != errFail {
+		t.Errorf("应返回fn的错误: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package rbadger
+
+import (
+	"github.com/dgraph-io/badger/v4"
+)
+
+// SAdd 向集合中添加一个或多个成员，返回新添加的成员数量（已存在的成员不计入）
+// 集合与哈希使用相同的存储方式：每个成员存储为值为空的key：key + "\x00" + member，
+// 因此key中不能包含"\x00"（否则返回 ErrInvalidHashKey），且同名的集合与哈希会共享数据
+// 示例：
+//
+//	n, err := db.SAdd("tags:item1", "go", "db", "go")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("新增%d个成员\n", n) // 新增2个成员
+func (b *BadgerDB) SAdd(key string, members ...string) (int, error) {
+	return b.updateMembers(key, members, true)
+}
+
+// SRem 从集合中移除一个或多个成员，返回实际被移除的成员数量
+// 示例：
+//
+//	n, err := db.SRem("tags:item1", "db")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SRem(key string, members ...string) (int, error) {
+	return b.updateMembers(key, members, false)
+}
+
+// SIsMember 判断member是否为集合的成员，只需一次点查询
+// 示例：
+//
+//	ok, err := db.SIsMember("tags:item1", "go")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SIsMember(key, member string) (bool, error) {
+	k, err := hashFieldKey(key, member)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	err = b.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(k)
+		if err == badger.ErrKeyNotFound {
+			return nil
+		}
+		found = err == nil
+		return err
+	})
+	return found, err
+}
+
+// SMembers 返回集合的所有成员，按字节序排列，集合不存在时返回空切片
+// 示例：
+//
+//	members, err := db.SMembers("tags:item1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SMembers(key string) ([]string, error) {
+	members := []string{}
+	err := b.scanMembers(key, func(member []byte) {
+		members = append(members, string(member))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SCard 返回集合的成员数量，只遍历key而不读取值
+// 示例：
+//
+//	n, err := db.SCard("tags:item1")
+func (b *BadgerDB) SCard(key string) (int, error) {
+	n := 0
+	err := b.scanMembers(key, func([]byte) {
+		n++
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// updateMembers 在一个事务中添加(add)或移除集合成员，返回实际发生变化的成员数量
+func (b *BadgerDB) updateMembers(key string, members []string, add bool) (int, error) {
+	keys := make([][]byte, 0, len(members))
+	for _, member := range members {
+		k, err := hashFieldKey(key, member)
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, k)
+	}
+	defer func() {
+		for _, k := range keys {
+			b.invalidate(string(k))
+		}
+	}()
+
+	var n int
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		n = 0
+		for _, k := range keys {
+			_, err := txn.Get(k)
+			if err != nil && err != badger.ErrKeyNotFound {
+				return err
+			}
+			exists := err == nil
+			switch {
+			case add && !exists:
+				err = txn.Set(k, nil)
+			case !add && exists:
+				err = txn.Delete(k)
+			default:
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !add {
+		b.noteDeletes(n)
+	}
+	return n, nil
+}
+
+// scanMembers 遍历集合的所有成员，fn收到的member只在回调期间有效
+func (b *BadgerDB) scanMembers(key string, fn func(member []byte)) error {
+	prefix, err := hashFieldKey(key, "")
+	if err != nil {
+		return err
+	}
+
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			fn(it.Item().Key()[len(prefix):])
+		}
+		return nil
+	})
+}
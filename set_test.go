@@ -0,0 +1,45 @@
+package rbadger
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSet 测试集合的成员操作
+func TestSet(t *testing.T) {
+	db := newTestDB(t)
+
+	if n, err := db.SAdd("tags", "go", "db", "go"); err != nil || n != 2 {
+		t.Fatalf("SAdd结果不正确: %d, %v", n, err)
+	}
+	if n, _ := db.SAdd("tags", "go", "kv"); n != 1 {
+		t.Errorf("已存在的成员不应计入: %d", n)
+	}
+
+	if ok, err := db.SIsMember("tags", "db"); err != nil || !ok {
+		t.Errorf("db应为集合成员: %v, %v", ok, err)
+	}
+	if ok, _ := db.SIsMember("tags", "java"); ok {
+		t.Error("java不应为集合成员")
+	}
+
+	members, err := db.SMembers("tags")
+	if err != nil || !reflect.DeepEqual(members, []string{"db", "go", "kv"}) {
+		t.Errorf("SMembers结果不正确: %v, %v", members, err)
+	}
+
+	if n, _ := db.SRem("tags", "db", "java"); n != 1 {
+		t.Errorf("SRem结果不正确: %d", n)
+	}
+	if n, _ := db.SCard("tags"); n != 2 {
+		t.Errorf("SCard结果不正确: %d", n)
+	}
+	if members, _ := db.SMembers("none"); len(members) != 0 {
+		t.Errorf("不存在的集合应返回空结果: %v", members)
+	}
+
+	if _, err := db.SAdd("bad\x00key", "m"); !errors.Is(err, ErrInvalidHashKey) {
+		t.Errorf("key包含分隔符时应返回ErrInvalidHashKey: %v", err)
+	}
+}
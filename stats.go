@@ -0,0 +1,179 @@
+package rbadger
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// EnableStatsKeyCount 设置 DumpStats 是否统计key数量
+// 统计key数量需要遍历整个数据库，默认关闭以保证 DumpStats 的开销较低
+func (b *BadgerDB) EnableStatsKeyCount(enabled bool) {
+	b.hookMu.Lock()
+	defer b.hookMu.Unlock()
+	b.statsKeyCount = enabled
+}
+
+// DumpStats 汇总数据库的运行状态，便于序列化为JSON后对外暴露
+// 包含LSM大小、值日志大小、各层级的表数量与大小，以及块缓存命中率（如果启用了块缓存）；
+// 通过 EnableStatsKeyCount 开启后还会包含key数量
+// 示例：
+//
+//	http.HandleFunc("/debug/badger", func(w http.ResponseWriter, r *http.Request) {
+//	    json.NewEncoder(w).Encode(db.DumpStats())
+//	})
+func (b *BadgerDB) DumpStats() map[string]interface{} {
+	stats := make(map[string]interface{})
+
+	lsm, vlog := b.db.Size()
+	stats["lsm_size"] = lsm
+	stats["vlog_size"] = vlog
+
+	var levels []map[string]interface{}
+	for _, l := range b.db.Levels() {
+		levels = append(levels, map[string]interface{}{
+			"level":      l.Level,
+			"num_tables": l.NumTables,
+			"size":       l.Size,
+		})
+	}
+	stats["levels"] = levels
+
+	if m := b.db.BlockCacheMetrics(); m != nil {
+		stats["block_cache_hits"] = m.Hits()
+		stats["block_cache_misses"] = m.Misses()
+		stats["block_cache_hit_ratio"] = m.Ratio()
+	}
+
+	b.hookMu.RLock()
+	withKeyCount := b.statsKeyCount
+	b.hookMu.RUnlock()
+	if withKeyCount {
+		if count, err := b.CountKeys(""); err == nil {
+			stats["key_count"] = count
+		}
+	}
+
+	return stats
+}
+
+// DBStats 数据库在磁盘上的大小与key数量估计
+type DBStats struct {
+	LSMSize       int64  // LSM树（SST文件）的大小
+	VLogSize      int64  // 值日志的大小
+	EstimatedKeys uint64 // 各SST文件中的key数量之和，包含旧版本和删除标记，不包含尚未落盘的内存表
+}
+
+// Stats 返回数据库的磁盘占用与估计的key数量，只读取badger维护的元数据，不遍历数据
+// 需要精确的key数量时应使用 CountKeys。数据库已关闭时返回 badger.ErrDBClosed
+// 示例：
+//
+//	stats, err := db.Stats()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("LSM: %d, 值日志: %d, 约%d个key\n", stats.LSMSize, stats.VLogSize, stats.EstimatedKeys)
+func (b *BadgerDB) Stats() (DBStats, error) {
+	if b.db.IsClosed() {
+		return DBStats{}, badger.ErrDBClosed
+	}
+
+	var stats DBStats
+	stats.LSMSize, stats.VLogSize = b.db.Size()
+	for _, t := range b.db.Tables() {
+		stats.EstimatedKeys += uint64(t.KeyCount)
+	}
+	return stats, nil
+}
+
+// EstimateSize 估计指定前缀下的数据在磁盘上占用的字节数
+// 只统计key范围完全落在该前缀内的SST文件，不包含内存表和值日志中的大值，结果仅供参考。
+// 数据库已关闭时返回 badger.ErrDBClosed
+// 示例：
+//
+//	size, err := db.EstimateSize("user:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) EstimateSize(prefix string) (uint64, error) {
+	if b.db.IsClosed() {
+		return 0, badger.ErrDBClosed
+	}
+	onDisk, _ := b.db.EstimateSize([]byte(prefix))
+	return onDisk, nil
+}
+
+// CacheStats 指定前缀下带过期时间存储的缓存数据汇总
+type CacheStats struct {
+	Entries       int       // 缓存数据总数，包含已过期但尚未删除的
+	Permanent     int       // 未设置过期时间的数量
+	Expired       int       // 已过期但尚未删除的数量
+	SoonestExpiry time.Time // 未过期数据中最早的过期时间，没有时为零值
+	LatestExpiry  time.Time // 未过期数据中最晚的过期时间，没有时为零值
+	Bytes         int64     // key与编码后的值的总字节数
+}
+
+// CacheReport 扫描指定前缀下带过期时间存储的数据并汇总
+// 在一个只读事务中完成，无法解码为 CacheType 的key不计入统计，也不会删除已过期的数据
+// 示例：
+//
+//	stats, err := db.CacheReport("session:")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("共%d个，已过期%d个，占用%d字节\n", stats.Entries, stats.Expired, stats.Bytes)
+func (b *BadgerDB) CacheReport(prefix string) (CacheStats, error) {
+	var stats CacheStats
+	var soonest, latest int64
+
+	now := time.Now().Unix()
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			err := item.Value(func(val []byte) error {
+				cache, err := b.decodeCache(val)
+				if err != nil {
+					return nil
+				}
+
+				stats.Entries++
+				stats.Bytes += int64(len(item.Key()) + len(val))
+				switch {
+				case cache.Expire == 0:
+					stats.Permanent++
+				case cache.expiredAt(now):
+					stats.Expired++
+				default:
+					if soonest == 0 || cache.Expire < soonest {
+						soonest = cache.Expire
+					}
+					if cache.Expire > latest {
+						latest = cache.Expire
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	if soonest > 0 {
+		stats.SoonestExpiry = time.Unix(soonest, 0)
+		stats.LatestExpiry = time.Unix(latest, 0)
+	}
+	return stats, nil
+}
@@ -0,0 +1,100 @@
+package rbadger
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestDumpStats 测试DumpStats方法
+func TestDumpStats(t *testing.T) {
+	db := newTestDB(t)
+
+	for i := 0; i < 10; i++ {
+		if err := db.SetS(fmt.Sprintf("k%d", i), "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := db.DumpStats()
+	if _, ok := stats["lsm_size"]; !ok {
+		t.Error("缺少lsm_size")
+	}
+	if _, ok := stats["key_count"]; ok {
+		t.Error("默认不应统计key数量")
+	}
+
+	db.EnableStatsKeyCount(true)
+	stats = db.DumpStats()
+	if stats["key_count"] != int64(10) {
+		t.Errorf("key数量不正确: %v", stats["key_count"])
+	}
+}
+
+// TestCacheReport 测试CacheReport方法
+func TestCacheReport(t *testing.T) {
+	db := newTestDB(t)
+
+	db.XSetS("c:permanent", "v")
+	db.XSetExS("c:soon", "v", time.Minute)
+	db.XSetExS("c:late", "v", time.Hour)
+	db.XSetExSecS("c:expired", "v", 1)
+	db.SetS("c:raw", "not cache")
+	time.Sleep(1100 * time.Millisecond)
+
+	stats, err := db.CacheReport("c:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 4 || stats.Permanent != 1 || stats.Expired != 1 {
+		t.Errorf("统计数量不正确: %+v", stats)
+	}
+	if !stats.SoonestExpiry.Before(stats.LatestExpiry) {
+		t.Errorf("过期时间范围不正确: %+v", stats)
+	}
+	if stats.Bytes <= 0 {
+		t.Errorf("字节数不正确: %d", stats.Bytes)
+	}
+}
+
+// TestStats 测试Stats与EstimateSize在数据落盘后的结果
+func TestStats(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db")
+	db, err := NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		db.SetS(fmt.Sprintf("user:%04d", i), "value")
+	}
+	db.Close()
+
+	// 重新打开后内存表已写入SST文件
+	db, err = NewBadgerDB(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := db.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.LSMSize <= 0 || stats.EstimatedKeys < 1000 {
+		t.Errorf("Stats结果不正确: %+v", stats)
+	}
+	if size, err := db.EstimateSize("user:"); err != nil || size == 0 {
+		t.Errorf("EstimateSize结果不正确: %d, %v", size, err)
+	}
+	if size, _ := db.EstimateSize("order:"); size != 0 {
+		t.Errorf("不存在的前缀应返回0: %d", size)
+	}
+
+	db.Close()
+	if _, err := db.Stats(); !errors.Is(err, badger.ErrDBClosed) {
+		t.Errorf("关闭后应返回ErrDBClosed: %v", err)
+	}
+}
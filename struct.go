@@ -0,0 +1,179 @@
+package rbadger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// structTag 控制 SetStruct/GetStruct 字段对应key名称的结构体标签，"-" 表示忽略该字段
+const structTag = "rbadger"
+
+// structField 描述一个参与存储的结构体字段
+type structField struct {
+	key   string
+	value reflect.Value
+}
+
+// structFields 返回结构体中所有参与存储的导出字段及其对应的key
+func structFields(prefix string, v reflect.Value) []structField {
+	t := v.Type()
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag := f.Tag.Get(structTag); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields = append(fields, structField{key: prefix + ":" + name, value: v.Field(i)})
+	}
+	return fields
+}
+
+// SetStruct 将结构体的每个导出字段以 prefix:字段名 为key分别存储为字符串
+// 字段名可通过 `rbadger:"name"` 标签指定，`rbadger:"-"` 表示忽略该字段；
+// 基本类型使用字符串形式存储，其他类型使用 JSON 编码。所有字段在同一个事务中写入
+// 示例：
+//
+//	type Config struct {
+//	    Host    string `rbadger:"host"`
+//	    Port    int    `rbadger:"port"`
+//	    Debug   bool   `rbadger:"debug"`
+//	}
+//	err := db.SetStruct("config", Config{Host: "localhost", Port: 8080})
+//	// 写入 config:host、config:port、config:debug 三个key
+func (b *BadgerDB) SetStruct(prefix string, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return errors.New("rbadger: SetStruct requires a struct or pointer to struct")
+	}
+
+	fields := structFields(prefix, rv)
+	defer b.purgeReadCache()
+	return b.db.Update(func(txn *badger.Txn) error {
+		for _, f := range fields {
+			data, err := formatField(f.value)
+			if err != nil {
+				return fmt.Errorf("rbadger: encode field %s: %w", f.key, err)
+			}
+			if err := b.cfg.checkValueLen(data); err != nil {
+				return fmt.Errorf("rbadger: field %s: %w", f.key, err)
+			}
+			if err := txn.Set([]byte(f.key), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetStruct 读取由 SetStruct 存储的字段并填充到v中，v必须是结构体指针
+// 不存在的key对应的字段保持原值不变
+// 示例：
+//
+//	var cfg Config
+//	if err := db.GetStruct("config", &cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetStruct(prefix string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("rbadger: GetStruct requires a pointer to struct")
+	}
+
+	fields := structFields(prefix, rv.Elem())
+	return b.db.View(func(txn *badger.Txn) error {
+		for _, f := range fields {
+			item, err := txn.Get([]byte(f.key))
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			err = item.Value(func(val []byte) error {
+				return parseField(f.value, val)
+			})
+			if err != nil {
+				return fmt.Errorf("rbadger: decode field %s: %w", f.key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// formatField 将字段值编码为字符串形式
+func formatField(v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return []byte(v.String()), nil
+	case reflect.Bool:
+		return []byte(strconv.FormatBool(v.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []byte(strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []byte(strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32, reflect.Float64:
+		return []byte(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return append([]byte{}, v.Bytes()...), nil
+		}
+	}
+	return json.Marshal(v.Interface())
+}
+
+// parseField 将字符串形式的数据解码到字段中
+func parseField(v reflect.Value, data []byte) error {
+	s := string(data)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(append([]byte{}, data...))
+			return nil
+		}
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
@@ -0,0 +1,60 @@
+package rbadger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSetStructGetStruct 测试SetStruct和GetStruct方法
+func TestSetStructGetStruct(t *testing.T) {
+	db := newTestDB(t)
+
+	type Config struct {
+		Host    string        `rbadger:"host"`
+		Port    int           `rbadger:"port"`
+		Debug   bool          `rbadger:"debug"`
+		Ratio   float64       `rbadger:"ratio"`
+		Timeout time.Duration `rbadger:"timeout"`
+		Tags    []string      `rbadger:"tags"`
+		Secret  string        `rbadger:"-"`
+		Name    string
+	}
+
+	in := Config{
+		Host:    "localhost",
+		Port:    8080,
+		Debug:   true,
+		Ratio:   0.75,
+		Timeout: 3 * time.Second,
+		Tags:    []string{"a", "b"},
+		Secret:  "hidden",
+		Name:    "svc",
+	}
+	if err := db.SetStruct("config", in); err != nil {
+		t.Fatal(err)
+	}
+
+	if port, _ := db.GetS("config:port"); port != "8080" {
+		t.Errorf("config:port的值不正确: %s", port)
+	}
+	if db.Exists("config:Secret") || db.Exists("config:-") {
+		t.Error("忽略的字段不应被存储")
+	}
+
+	// 单独修改一个字段
+	if err := db.SetS("config:port", "9090"); err != nil {
+		t.Fatal(err)
+	}
+
+	var out Config
+	if err := db.GetStruct("config", &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Port = 9090
+	in.Secret = ""
+	if out.Host != in.Host || out.Port != in.Port || out.Debug != in.Debug ||
+		out.Ratio != in.Ratio || out.Timeout != in.Timeout || len(out.Tags) != 2 ||
+		out.Secret != "" || out.Name != in.Name {
+		t.Errorf("读取结果不正确: %+v", out)
+	}
+}
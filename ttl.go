@@ -0,0 +1,70 @@
+package rbadger
+
+import (
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// SetEx 使用 badger 原生的TTL存储带过期时间的数据
+// 值按原样存储，不经过 CacheType 编码，过期由 badger 自行处理，比 XSetEx 更快且占用更少空间。
+// 读取使用 Get/GetS，剩余时间使用 TTL 查询；不能与 X 系列方法混用
+// 示例：
+//
+//	err := db.SetEx("session:1", []byte("data"), 30*time.Minute)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetEx(key string, value []byte, ttl time.Duration) error {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(key)
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry([]byte(key), value).WithTTL(ttl))
+	})
+}
+
+// SetExS 使用 badger 原生的TTL存储带过期时间的字符串
+// 示例：
+//
+//	err := db.SetExS("session:1", "data", 30*time.Minute)
+func (b *BadgerDB) SetExS(key string, value string, ttl time.Duration) error {
+	return b.SetEx(key, []byte(value), ttl)
+}
+
+// TTL 返回由 SetEx 写入的key的剩余生存时间（秒）
+// key不存在或已过期时返回-2，未设置过期时间时返回-1
+// 示例：
+//
+//	ttl, err := db.TTL("session:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) TTL(key string) (int64, error) {
+	var ttl int64 = -2
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		expiresAt := item.ExpiresAt()
+		if expiresAt == 0 {
+			ttl = -1
+			return nil
+		}
+		ttl = int64(expiresAt) - time.Now().Unix()
+		if ttl < 0 {
+			ttl = 0
+		}
+		return nil
+	})
+	if err == badger.ErrKeyNotFound {
+		return -2, nil
+	}
+	if err != nil {
+		return -2, err
+	}
+	return ttl, nil
+}
@@ -0,0 +1,37 @@
+package rbadger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSetEx 测试使用badger原生TTL存储
+func TestSetEx(t *testing.T) {
+	db := newTestDB(t)
+	db.EnableReadCache(10, time.Minute)
+
+	if err := db.SetExS("session", "data", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.GetS("session"); err != nil || v != "data" {
+		t.Errorf("读取结果不正确: %q, %v", v, err)
+	}
+	if ttl, _ := db.TTL("session"); ttl <= 0 || ttl > 3600 {
+		t.Errorf("剩余时间不正确: %d", ttl)
+	}
+
+	db.SetS("permanent", "v")
+	if ttl, _ := db.TTL("permanent"); ttl != -1 {
+		t.Errorf("未设置过期时间时应返回-1: %d", ttl)
+	}
+	if ttl, _ := db.TTL("missing"); ttl != -2 {
+		t.Errorf("key不存在时应返回-2: %d", ttl)
+	}
+
+	db.SetExS("short", "v", time.Second)
+	db.GetS("short")
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := db.GetS("short"); err == nil {
+		t.Error("过期后不应读取到值")
+	}
+}
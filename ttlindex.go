@@ -0,0 +1,192 @@
+package rbadger
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// ttlIndexPrefix 独立过期时间索引使用的key前缀
+const ttlIndexPrefix = reservedPrefix + "ttl:"
+
+// ttlIndexKey 返回key对应的过期时间索引key
+func ttlIndexKey(key string) []byte {
+	return []byte(ttlIndexPrefix + key)
+}
+
+// SetExIndexed 存储原始值，并将过期时间单独记录在索引中
+// 与 XSetEx 不同，值本身不会被 CacheType 包装，可以直接通过 Get 读取；
+// 修改过期时间时也无需重写（可能很大的）值。expires<=0 表示永不过期
+// 使用该方式管理的key应统一通过 SetExIndexed/GetExIndexed/DelIndexed 读写，
+// 否则索引中残留的过期时间可能导致新写入的值被视为已过期
+// 示例：
+//
+//	err := db.SetExIndexed("report:1", data, time.Hour)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) SetExIndexed(key string, value []byte, expires time.Duration) error {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return err
+	}
+	defer b.invalidate(key)
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Set([]byte(key), value); err != nil {
+			return err
+		}
+		if expires <= 0 {
+			return txn.Delete(ttlIndexKey(key))
+		}
+		expire := time.Now().Add(expires).Unix()
+		return txn.Set(ttlIndexKey(key), []byte(strconv.FormatInt(expire, 10)))
+	})
+}
+
+// GetExIndexed 获取由 SetExIndexed 存储的值
+// 根据过期时间索引判断是否过期，过期时返回nil，值及其索引由后台任务删除，读取本身不产生写事务
+// 示例：
+//
+//	value, err := db.GetExIndexed("report:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if value == nil {
+//	    fmt.Println("key不存在或已过期")
+//	}
+func (b *BadgerDB) GetExIndexed(key string) ([]byte, error) {
+	var valCopy []byte
+	var expired bool
+	err := b.db.View(func(txn *badger.Txn) error {
+		expire, err := readTTLIndex(txn, key)
+		if err != nil {
+			return err
+		}
+		if expire > 0 && expire <= time.Now().Unix() {
+			expired = true
+			return nil
+		}
+
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		valCopy, err = item.ValueCopy(nil)
+		return err
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		b.expireIndexedKeys(key)
+		return nil, nil
+	}
+	return valCopy, nil
+}
+
+// ExpireIndexed 修改由 SetExIndexed 存储的key的过期时间，不会重写值本身
+// expires<=0 表示移除过期时间
+// 示例：
+//
+//	err := db.ExpireIndexed("report:1", 2*time.Hour)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ExpireIndexed(key string, expires time.Duration) error {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get([]byte(key)); err != nil {
+			return err
+		}
+		if expires <= 0 {
+			return txn.Delete(ttlIndexKey(key))
+		}
+		expire := time.Now().Add(expires).Unix()
+		return txn.Set(ttlIndexKey(key), []byte(strconv.FormatInt(expire, 10)))
+	})
+	return notFound(key, err)
+}
+
+// DelIndexed 删除由 SetExIndexed 存储的值及其过期时间索引
+// 示例：
+//
+//	err := db.DelIndexed("report:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) DelIndexed(key string) error {
+	defer b.invalidate(key)
+
+	return b.db.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete([]byte(key)); err != nil {
+			return err
+		}
+		return txn.Delete(ttlIndexKey(key))
+	})
+}
+
+// readTTLIndex 读取key的过期时间索引，没有索引时返回0
+func readTTLIndex(txn *badger.Txn, key string) (int64, error) {
+	item, err := txn.Get(ttlIndexKey(key))
+	if err == badger.ErrKeyNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var expire int64
+	err = item.Value(func(val []byte) error {
+		expire, err = strconv.ParseInt(string(val), 10, 64)
+		return err
+	})
+	return expire, err
+}
+
+// deleteExpiredIndexed 分批删除给定的key及其过期时间索引，删除前在同一事务中确认其仍已过期
+func (b *BadgerDB) deleteExpiredIndexed(keys [][]byte) (int, error) {
+	deleted := 0
+	for start := 0; start < len(keys); start += expireBatchSize {
+		end := start + expireBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		var removed [][]byte
+		err := b.updateWithRetry(func(txn *badger.Txn) error {
+			removed = removed[:0]
+			now := time.Now().Unix()
+			for _, key := range keys[start:end] {
+				expire, err := readTTLIndex(txn, string(key))
+				if err != nil {
+					return err
+				}
+				if expire == 0 || expire > now {
+					continue
+				}
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+				if err := txn.Delete(ttlIndexKey(string(key))); err != nil {
+					return err
+				}
+				removed = append(removed, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += len(removed)
+		b.noteDeletes(len(removed))
+		for _, key := range removed {
+			b.invalidate(string(key))
+		}
+		b.fireExpire(removed...)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,40 @@
+package rbadger
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSetExIndexed 测试独立过期时间索引
+func TestSetExIndexed(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetExIndexed("doc", []byte("raw"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// 值保持原样，可直接通过Get读取
+	if val, _ := db.GetS("doc"); val != "raw" {
+		t.Errorf("值应按原样存储，实际为%q", val)
+	}
+	val, err := db.GetExIndexed("doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(val) != "raw" {
+		t.Errorf("值不正确: %s", val)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	val, err = db.GetExIndexed("doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val != nil {
+		t.Error("过期后应返回nil")
+	}
+	if !waitDeleted(db, "doc") {
+		t.Error("过期的值应该已被后台任务删除")
+	}
+}
@@ -0,0 +1,45 @@
+package rbadger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// newTestDB 创建一个用于测试的内存数据库
+func newTestDB(t *testing.T) *BadgerDB {
+	t.Helper()
+
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// waitDeleted 等待后台任务删除key，超时仍存在时返回false
+func waitDeleted(db *BadgerDB, key string) bool {
+	deadline := time.Now().Add(4 * expireFlushInterval)
+	for time.Now().Before(deadline) {
+		if !db.Exists(key) {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return !db.Exists(key)
+}
+
+// newBenchDB 创建一个用于基准测试的磁盘数据库
+func newBenchDB(b *testing.B) *BadgerDB {
+	b.Helper()
+
+	opts := badger.DefaultOptions(b.TempDir()).WithLogger(nil)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,227 @@
+package rbadger
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// CurrentVersion 返回数据库当前已提交的最大版本号，可作为增量同步的检查点
+// 示例：
+//
+//	checkpoint := db.CurrentVersion()
+//	// ... 之后
+//	keys, err := db.KeysModifiedSince(checkpoint)
+func (b *BadgerDB) CurrentVersion() uint64 {
+	return b.db.MaxVersion()
+}
+
+// KeysModifiedSince 返回最新提交版本大于version的key列表，用于构建变更流
+// 只比较每个key的最新版本，因此垃圾回收丢弃旧版本不会影响结果；
+// 但被删除的key不会出现在结果中，需要感知删除时应使用 Watch
+// 示例：
+//
+//	keys, err := db.KeysModifiedSince(checkpoint)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	checkpoint = db.CurrentVersion()
+func (b *BadgerDB) KeysModifiedSince(version uint64) ([]string, error) {
+	var keys []string
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			if isReservedKey(item.Key()) {
+				continue
+			}
+			if item.Version() > version {
+				keys = append(keys, string(item.Key()))
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// GetWithVersion 获取指定key的值及其当前提交版本号
+// 示例：
+//
+//	value, version, err := db.GetWithVersion("doc:1")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) GetWithVersion(key string) ([]byte, uint64, error) {
+	var valCopy []byte
+	var version uint64
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+		version = item.Version()
+		valCopy, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, 0, notFound(key, err)
+	}
+	return valCopy, version, nil
+}
+
+// SetIfVersion 仅当key的当前提交版本号等于expectedVersion时写入新值，返回是否写入成功
+// expectedVersion 为0时表示仅在key不存在时写入。
+// 相比按值比较的CAS，对于较大的值无需逐字节比较
+// 示例：
+//
+//	value, version, _ := db.GetWithVersion("doc:1")
+//	ok, err := db.SetIfVersion("doc:1", update(value), version)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !ok {
+//	    fmt.Println("数据已被其他写入者修改")
+//	}
+func (b *BadgerDB) SetIfVersion(key string, value []byte, expectedVersion uint64) (bool, error) {
+	if err := b.cfg.checkValueLen(value); err != nil {
+		return false, err
+	}
+	defer b.invalidate(key)
+
+	applied := false
+	err := b.updateWithRetry(func(txn *badger.Txn) error {
+		applied = false
+
+		var current uint64
+		item, err := txn.Get([]byte(key))
+		if err == nil {
+			current = item.Version()
+		} else if err != badger.ErrKeyNotFound {
+			return err
+		}
+
+		if current != expectedVersion {
+			return nil
+		}
+		applied = true
+		return txn.Set([]byte(key), value)
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
+
+// TrimVersions 只保留key最新的keep个版本，返回将被移除的旧版本数量
+// 非托管模式下无法直接删除指定版本，因此该方法将需要保留的版本按从旧到新的顺序重新写入，
+// 并在其中最旧的一个上设置丢弃标记（WithDiscard），之后压缩时会移除该标记之前的所有版本。
+// 旧版本在压缩前仍可通过 AllVersions 迭代看到，保留版本的版本号会变为新的值。
+// 只有在 badger.Options.WithNumVersionsToKeep 大于1时才会保留多个版本；
+// 从最新版本向前遇到删除或过期的版本即停止。
+// 注意：重写期间读取方会看到key的值依次回退到较旧的保留版本，直到最后一次重写恢复为最新值，
+// 因此不应在值被频繁读取时执行。每次重写前都会确认key未被其他写入修改，
+// 发现修改时返回 badger.ErrConflict 并停止，此时并发写入的值保持为最新值
+// 示例：
+//
+//	removed, err := db.TrimVersions("audit:order:1", 10)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) TrimVersions(key string, keep int) (int, error) {
+	if keep < 1 {
+		return 0, errors.New("rbadger: keep must be at least 1")
+	}
+
+	type version struct {
+		value     []byte
+		version   uint64
+		expiresAt uint64
+		meta      byte
+	}
+	var versions []version
+	older := 0
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.AllVersions = true
+		opts.Prefix = []byte(key)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		live := true
+		for it.Seek([]byte(key)); it.ValidForPrefix([]byte(key)); it.Next() {
+			item := it.Item()
+			if string(item.Key()) != key {
+				break
+			}
+			if live && item.IsDeletedOrExpired() {
+				live = false
+			}
+			if !live || len(versions) >= keep {
+				older++
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			versions = append(versions, version{
+				value:     value,
+				version:   item.Version(),
+				expiresAt: item.ExpiresAt(),
+				meta:      item.UserMeta(),
+			})
+		}
+		return nil
+	})
+	if err != nil || older == 0 || len(versions) == 0 {
+		return 0, err
+	}
+	defer b.invalidate(key)
+
+	// 从保留版本中最旧的开始重写，只有第一次写入带丢弃标记
+	// 每次重写前确认当前最新版本仍是预期的版本（第一次为原最新版本，之后为上一次重写的版本），
+	// 期间有其他写入时返回 badger.ErrConflict 并停止，保证并发写入的值不会被旧版本覆盖
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		err := b.db.Update(func(txn *badger.Txn) error {
+			item, err := txn.Get([]byte(key))
+			if err != nil {
+				return err
+			}
+			if i == len(versions)-1 {
+				if item.Version() != versions[0].version {
+					return badger.ErrConflict
+				}
+			} else {
+				prev := versions[i+1]
+				if item.ExpiresAt() != prev.expiresAt || item.UserMeta() != prev.meta {
+					return badger.ErrConflict
+				}
+				if err := item.Value(func(val []byte) error {
+					if !bytes.Equal(val, prev.value) {
+						return badger.ErrConflict
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
+
+			e := badger.NewEntry([]byte(key), v.value).WithMeta(v.meta)
+			e.ExpiresAt = v.expiresAt
+			if i == len(versions)-1 {
+				e = e.WithDiscard()
+			}
+			return txn.SetEntry(e)
+		})
+		if err != nil {
+			return 0, notFound(key, err)
+		}
+	}
+	return older, nil
+}
@@ -0,0 +1,122 @@
+package rbadger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// TestKeysModifiedSince 测试KeysModifiedSince方法
+func TestKeysModifiedSince(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.SetS("old", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint := db.CurrentVersion()
+
+	if err := db.SetS("new", "v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := db.KeysModifiedSince(checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "new" {
+		t.Errorf("变更的key不正确: %v", keys)
+	}
+}
+
+// TestSetIfVersion 测试SetIfVersion方法
+func TestSetIfVersion(t *testing.T) {
+	db := newTestDB(t)
+
+	ok, err := db.SetIfVersion("doc", []byte("v1"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("key不存在时期望版本0应写入成功")
+	}
+
+	_, version, err := db.GetWithVersion("doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 其他写入者修改了数据
+	if err := db.SetS("doc", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err = db.SetIfVersion("doc", []byte("v2"), version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("版本不匹配时不应写入")
+	}
+
+	_, version, _ = db.GetWithVersion("doc")
+	ok, err = db.SetIfVersion("doc", []byte("v2"), version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("版本匹配时应写入成功")
+	}
+	if val, _ := db.GetS("doc"); val != "v2" {
+		t.Errorf("值不正确: %s", val)
+	}
+}
+
+// TestTrimVersions 测试TrimVersions方法
+func TestTrimVersions(t *testing.T) {
+	opts := badger.DefaultOptions("").WithInMemory(true).WithLogger(nil).WithNumVersionsToKeep(10)
+	db, err := NewBadgerDBWithOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for i := 1; i <= 5; i++ {
+		if err := db.SetS("audit", fmt.Sprintf("v%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.SetS("audit2", "other")
+
+	removed, err := db.TrimVersions("audit", 2)
+	if err != nil || removed != 3 {
+		t.Fatalf("移除数量不正确: %d, %v", removed, err)
+	}
+	if v, _ := db.GetS("audit"); v != "v5" {
+		t.Errorf("最新值不正确: %q", v)
+	}
+
+	// 最新的两个版本为重写后的保留版本，最旧的保留版本带有丢弃标记
+	var values []string
+	db.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.AllVersions = true
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Seek([]byte("audit")); it.Valid() && string(it.Item().Key()) == "audit"; it.Next() {
+			val, _ := it.Item().ValueCopy(nil)
+			values = append(values, string(val))
+			if len(values) == 2 && !it.Item().DiscardEarlierVersions() {
+				t.Error("最旧的保留版本应带有丢弃标记")
+			}
+		}
+		return nil
+	})
+	if len(values) < 2 || values[0] != "v5" || values[1] != "v4" {
+		t.Errorf("保留的版本不正确: %v", values)
+	}
+
+	if removed, err := db.TrimVersions("audit2", 2); err != nil || removed != 0 {
+		t.Errorf("版本数不超过keep时不应移除: %d, %v", removed, err)
+	}
+}
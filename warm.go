@@ -0,0 +1,53 @@
+package rbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// warmCheckInterval 预热时每遍历多少个key检查一次ctx是否被取消
+const warmCheckInterval = 1000
+
+// Warm 读取指定前缀下所有key的值，使其加载到 badger 的块缓存中
+// 适合在服务启动后对热点前缀进行预热，以换取稳定的读取延迟
+// 示例：
+//
+//	if err := db.Warm("config:"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) Warm(prefix string) error {
+	return b.WarmCtx(context.Background(), prefix)
+}
+
+// WarmCtx 与 Warm 相同，但可以通过ctx取消预热，取消时返回ctx的错误
+// 示例：
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := db.WarmCtx(ctx, "user:"); err != nil {
+//	    log.Printf("预热未完成: %v", err)
+//	}
+func (b *BadgerDB) WarmCtx(ctx context.Context, prefix string) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		n := 0
+		for it.Rewind(); it.Valid(); it.Next() {
+			if n%warmCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			n++
+
+			if err := it.Item().Value(func(val []byte) error { return nil }); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package rbadger
+
+import (
+	"context"
+
+	"github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/pb"
+)
+
+// Watch 订阅指定前缀下key的变化，每次有写入提交时对每个变化的key调用fn
+// 删除的key以value为nil回调（与写入空值无法区分），包内部保留的key不会回调。
+// 该方法会阻塞直到ctx结束或数据库关闭，ctx结束时返回ctx的错误，因此通常在单独的goroutine中运行；
+// fn 在订阅的goroutine中串行调用，耗时较长时会延迟后续通知的处理
+// 示例：
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//	go db.Watch(ctx, "config:", func(key string, value []byte) {
+//	    localCache.Delete(key)
+//	})
+func (b *BadgerDB) Watch(ctx context.Context, prefix string, fn func(key string, value []byte)) error {
+	return b.db.Subscribe(ctx, func(list *badger.KVList) error {
+		for _, kv := range list.Kv {
+			if isReservedKey(kv.Key) {
+				continue
+			}
+			value := kv.Value
+			if len(value) == 0 {
+				value = nil
+			}
+			fn(string(kv.Key), value)
+		}
+		return nil
+	}, []pb.Match{{Prefix: []byte(prefix)}})
+}
+
+// WatchX 与 Watch 相同，但将值解码为带过期时间的缓存数据，回调的value为其中的数据
+// 删除的key以value为nil回调，无法解码为缓存数据的写入会被忽略
+// 示例：
+//
+//	go db.WatchX(ctx, "session:", func(key string, value []byte) {
+//	    if value == nil {
+//	        log.Printf("会话 %s 已删除", key)
+//	    }
+//	})
+func (b *BadgerDB) WatchX(ctx context.Context, prefix string, fn func(key string, value []byte)) error {
+	return b.Watch(ctx, prefix, func(key string, value []byte) {
+		if value == nil {
+			fn(key, nil)
+			return
+		}
+		cache, err := b.decodeCache(value)
+		if err != nil {
+			return
+		}
+		fn(key, cache.Data)
+	})
+}
@@ -0,0 +1,87 @@
+package rbadger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchEvent 订阅收到的一次变化
+type watchEvent struct {
+	key     string
+	value   string
+	deleted bool
+}
+
+// startWatch 在后台运行订阅并收集回调，返回读取收集结果的函数
+func startWatch(t *testing.T, watch func(ctx context.Context, fn func(key string, value []byte)) error) func() []watchEvent {
+	t.Helper()
+
+	var mu sync.Mutex
+	var events []watchEvent
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watch(ctx, func(key string, value []byte) {
+			mu.Lock()
+			events = append(events, watchEvent{key: key, value: string(value), deleted: value == nil})
+			mu.Unlock()
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("ctx取消后应返回context.Canceled: %v", err)
+		}
+	})
+	// 等待订阅生效
+	time.Sleep(50 * time.Millisecond)
+
+	return func() []watchEvent {
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]watchEvent{}, events...)
+	}
+}
+
+// TestWatch 测试订阅前缀下的写入与删除
+func TestWatch(t *testing.T) {
+	db := newTestDB(t)
+	collect := startWatch(t, func(ctx context.Context, fn func(string, []byte)) error {
+		return db.Watch(ctx, "config:", fn)
+	})
+
+	db.SetS("config:a", "1")
+	db.SetS("other", "x")
+	db.Del("config:a")
+
+	events := collect()
+	if len(events) != 2 {
+		t.Fatalf("期望2次回调，实际为%v", events)
+	}
+	if events[0].key != "config:a" || events[0].value != "1" {
+		t.Errorf("写入回调不正确: %+v", events[0])
+	}
+	if !events[1].deleted {
+		t.Errorf("删除应以nil值回调: %+v", events[1])
+	}
+}
+
+// TestWatchX 测试订阅解码后的缓存数据
+func TestWatchX(t *testing.T) {
+	db := newTestDB(t)
+	collect := startWatch(t, func(ctx context.Context, fn func(string, []byte)) error {
+		return db.WatchX(ctx, "s:", fn)
+	})
+
+	db.XSetExS("s:1", "data", time.Hour)
+	db.SetS("s:raw", "not cache")
+
+	events := collect()
+	if len(events) != 1 || events[0].key != "s:1" || events[0].value != "data" {
+		t.Errorf("WatchX回调不正确: %v", events)
+	}
+}
@@ -0,0 +1,89 @@
+package rbadger
+
+import "context"
+
+// worker 表示一个后台任务
+type worker struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startWorker 以name启动一个后台任务，同名任务已在运行时不会重复启动并返回false
+// 数据库正在关闭或已关闭（stopWorkers 之后）时同样不会启动并返回false
+// fn 应在stop被关闭后尽快返回
+func (b *BadgerDB) startWorker(name string, fn func(stop <-chan struct{})) bool {
+	b.workersMu.Lock()
+	defer b.workersMu.Unlock()
+
+	if b.workersClosed {
+		return false
+	}
+	if b.workers == nil {
+		b.workers = make(map[string]*worker)
+	}
+	if _, ok := b.workers[name]; ok {
+		return false
+	}
+
+	w := &worker{stop: make(chan struct{}), done: make(chan struct{})}
+	b.workers[name] = w
+	go func() {
+		defer close(w.done)
+		fn(w.stop)
+
+		// 任务自行结束时移除记录，以便之后可以再次启动
+		b.workersMu.Lock()
+		if b.workers[name] == w {
+			delete(b.workers, name)
+		}
+		b.workersMu.Unlock()
+	}()
+	return true
+}
+
+// stopWorker 停止指定名称的后台任务并等待其退出
+func (b *BadgerDB) stopWorker(name string) {
+	b.workersMu.Lock()
+	w, ok := b.workers[name]
+	delete(b.workers, name)
+	b.workersMu.Unlock()
+
+	if ok {
+		close(w.stop)
+		<-w.done
+	}
+}
+
+// stopWorkers 停止所有后台任务并等待其退出，之后不能再启动新的后台任务
+func (b *BadgerDB) stopWorkers() {
+	b.stopWorkersCtx(context.Background())
+}
+
+// stopWorkersCtx 停止所有后台任务并等待其退出，ctx结束时不再等待并返回ctx的错误
+// 停止与禁止启动在同一把锁下完成，避免与 Close 并发的读取或删除重新启动后台任务
+func (b *BadgerDB) stopWorkersCtx(ctx context.Context) error {
+	b.workersMu.Lock()
+	workers := b.workers
+	b.workers = nil
+	b.workersClosed = true
+	b.workersMu.Unlock()
+
+	for _, w := range workers {
+		close(w.stop)
+	}
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// resumeWorkers 允许在 stopWorkers 之后重新启动后台任务，用于重新打开数据库后
+func (b *BadgerDB) resumeWorkers() {
+	b.workersMu.Lock()
+	b.workersClosed = false
+	b.workersMu.Unlock()
+}
@@ -0,0 +1,225 @@
+package rbadger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// 有序集合的每个成员对应两个key：
+//   - 分数索引 key + "\x01" + 编码后的分数(8字节) + member，值为空，按字节序迭代即为分数顺序
+//   - 成员索引 key + "\x02" + member，值为编码后的分数，用于 ZScore 和更新时删除旧的分数索引
+//
+// key中不能包含这两个分隔符，否则返回 ErrInvalidZSetKey
+const (
+	zsetScoreSep  = "\x01"
+	zsetMemberSep = "\x02"
+)
+
+// checkZSetKey 检查有序集合的key是否包含分隔符
+func checkZSetKey(key string) error {
+	if strings.ContainsAny(key, zsetScoreSep+zsetMemberSep) {
+		return fmt.Errorf("%w: %q", ErrInvalidZSetKey, key)
+	}
+	return nil
+}
+
+// zsetScorePrefix 返回有序集合分数索引的前缀
+func zsetScorePrefix(key string) []byte {
+	return []byte(key + zsetScoreSep)
+}
+
+// zsetScoreKey 返回成员的分数索引key
+func zsetScoreKey(key, member string, score []byte) []byte {
+	k := zsetScorePrefix(key)
+	k = append(k, score...)
+	return append(k, member...)
+}
+
+// zsetMemberKey 返回成员索引key
+func zsetMemberKey(key, member string) []byte {
+	return []byte(key + zsetMemberSep + member)
+}
+
+// encodeScore 将分数编码为可按字节序比较的8字节
+// 正数翻转符号位，负数翻转所有位，使编码后的字节序与数值大小一致
+func encodeScore(score float64) []byte {
+	bits := math.Float64bits(score)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, bits)
+	return buf
+}
+
+// decodeScore 解码 encodeScore 编码的分数
+func decodeScore(buf []byte) float64 {
+	bits := binary.BigEndian.Uint64(buf)
+	if bits&(1<<63) != 0 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits)
+}
+
+// ZAdd 将成员以指定分数加入有序集合，成员已存在时更新其分数
+// 更新分数索引与成员索引在同一个事务中完成，遇到并发冲突时自动重试
+// 示例：
+//
+//	err := db.ZAdd("leaderboard", "alice", 1500)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ZAdd(key, member string, score float64) error {
+	if err := checkZSetKey(key); err != nil {
+		return err
+	}
+	if math.IsNaN(score) {
+		return fmt.Errorf("rbadger: score of member %q is NaN", member)
+	}
+
+	memberKey := zsetMemberKey(key, member)
+	encoded := encodeScore(score)
+	return b.updateWithRetry(func(txn *badger.Txn) error {
+		item, err := txn.Get(memberKey)
+		if err != nil && err != badger.ErrKeyNotFound {
+			return err
+		}
+		if err == nil {
+			old, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := txn.Delete(zsetScoreKey(key, member, old)); err != nil {
+				return err
+			}
+		}
+
+		if err := txn.Set(memberKey, encoded); err != nil {
+			return err
+		}
+		return txn.Set(zsetScoreKey(key, member, encoded), nil)
+	})
+}
+
+// ZScore 返回成员在有序集合中的分数，成员不存在时返回 ErrNotFound
+// 示例：
+//
+//	score, err := db.ZScore("leaderboard", "alice")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ZScore(key, member string) (float64, error) {
+	if err := checkZSetKey(key); err != nil {
+		return 0, err
+	}
+
+	var score float64
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(zsetMemberKey(key, member))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			if len(val) != 8 {
+				return fmt.Errorf("%w: member %q of %q", ErrInvalidFloat, member, key)
+			}
+			score = decodeScore(val)
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, notFound(member, err)
+	}
+	return score, nil
+}
+
+// ZRange 按分数从低到高返回下标在[start, stop]范围内的成员，分数相同时按成员名排序
+// 下标规则与 LRange 相同，负数表示从末尾倒数
+// 示例：
+//
+//	members, err := db.ZRange("leaderboard", 0, -1)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ZRange(key string, start, stop int) ([]string, error) {
+	return b.zrange(key, start, stop, false)
+}
+
+// ZRevRange 按分数从高到低返回下标在[start, stop]范围内的成员，适合获取排行榜前N名
+// 示例：
+//
+//	top10, err := db.ZRevRange("leaderboard", 0, 9)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *BadgerDB) ZRevRange(key string, start, stop int) ([]string, error) {
+	return b.zrange(key, start, stop, true)
+}
+
+// zrange 按分数顺序(reverse为从高到低)返回下标在[start, stop]范围内的成员
+func (b *BadgerDB) zrange(key string, start, stop int, reverse bool) ([]string, error) {
+	if err := checkZSetKey(key); err != nil {
+		return nil, err
+	}
+
+	prefix := zsetScorePrefix(key)
+	members := []string{}
+	err := b.db.View(func(txn *badger.Txn) error {
+		// 负数下标需要先知道成员数量
+		if start < 0 || stop < 0 {
+			n := 0
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = prefix
+			opts.PrefetchValues = false
+			it := txn.NewIterator(opts)
+			for it.Rewind(); it.Valid(); it.Next() {
+				n++
+			}
+			it.Close()
+
+			if start < 0 {
+				start += n
+			}
+			if stop < 0 {
+				stop += n
+			}
+		}
+		if start < 0 {
+			start = 0
+		}
+		if start > stop {
+			return nil
+		}
+
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Reverse = reverse
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		if reverse {
+			seekLastInPrefix(it, prefix)
+		} else {
+			it.Seek(prefix)
+		}
+		for i := 0; it.ValidForPrefix(prefix) && i <= stop; it.Next() {
+			if i >= start {
+				members = append(members, string(it.Item().Key()[len(prefix)+8:]))
+			}
+			i++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
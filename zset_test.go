@@ -0,0 +1,57 @@
+package rbadger
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestZSet 测试有序集合在正负分数下的排序
+func TestZSet(t *testing.T) {
+	db := newTestDB(t)
+
+	scores := map[string]float64{
+		"a": -100.5,
+		"b": -1,
+		"c": 0,
+		"d": 0.25,
+		"e": 42,
+		"f": math.Inf(1),
+		"g": math.Inf(-1),
+	}
+	for member, score := range scores {
+		if err := db.ZAdd("board", member, score); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 干扰数据：相邻前缀的有序集合不应被读到
+	db.ZAdd("board2", "x", 1)
+
+	members, err := db.ZRange("board", 0, -1)
+	if err != nil || !reflect.DeepEqual(members, []string{"g", "a", "b", "c", "d", "e", "f"}) {
+		t.Errorf("ZRange结果不正确: %v, %v", members, err)
+	}
+	if top, _ := db.ZRevRange("board", 0, 2); !reflect.DeepEqual(top, []string{"f", "e", "d"}) {
+		t.Errorf("ZRevRange结果不正确: %v", top)
+	}
+	if tail, _ := db.ZRange("board", -2, -1); !reflect.DeepEqual(tail, []string{"e", "f"}) {
+		t.Errorf("负数下标结果不正确: %v", tail)
+	}
+
+	// 更新分数后旧的分数索引应被删除
+	db.ZAdd("board", "a", 100)
+	if s, err := db.ZScore("board", "a"); err != nil || s != 100 {
+		t.Errorf("ZScore结果不正确: %v, %v", s, err)
+	}
+	if members, _ := db.ZRange("board", 0, -1); len(members) != 7 || members[5] != "a" {
+		t.Errorf("更新分数后排序不正确: %v", members)
+	}
+
+	if _, err := db.ZScore("board", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("成员不存在时应返回ErrNotFound: %v", err)
+	}
+	if err := db.ZAdd("bad\x01", "m", 1); !errors.Is(err, ErrInvalidZSetKey) {
+		t.Errorf("key包含分隔符时应返回ErrInvalidZSetKey: %v", err)
+	}
+}